@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/nostr"
+)
+
+// handleArticleCommand dispatches the `nostr article <subcommand>` family
+func handleArticleCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: nostr article publish -file <post.md> [flags]")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "publish":
+		handleArticlePublishCommand(args[1:])
+
+	default:
+		fmt.Printf("Unknown article command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleArticlePublishCommand(args []string) {
+	cmd := flag.NewFlagSet("article publish", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	file := cmd.String("file", "", "Markdown file with optional YAML front matter")
+	identifier := cmd.String("d", "", "Article identifier, overriding the front matter (defaults to the file name)")
+	draft := cmd.Bool("draft", false, "Publish as a draft (kind 30024)")
+	clientID := cmd.String("client", "nostr_demo_golang", "Client identifier")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the publish report as JSON")
+	parseWithProfile(cmd, args, keys, false)
+
+	if *file == "" {
+		fmt.Println("Usage: nostr article publish -file <post.md> [-draft]")
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		fmt.Printf("Error reading article: %v\n", err)
+		os.Exit(1)
+	}
+
+	article, err := nostr.ParseArticle(data)
+	if err != nil {
+		fmt.Printf("Error parsing article: %v\n", err)
+		os.Exit(1)
+	}
+
+	// The identifier must stay the same for updates, so fall back to the file name rather than the title
+	if *identifier != "" {
+		article.Identifier = *identifier
+	}
+	if article.Identifier == "" {
+		article.Identifier = strings.TrimSuffix(filepath.Base(*file), filepath.Ext(*file))
+	}
+
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, naddr, err := client.PublishArticle(ctx, article, *draft, *clientID)
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error publishing article: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		if *draft {
+			fmt.Println("Draft saved successfully!")
+		} else {
+			fmt.Println("Article published successfully!")
+		}
+		fmt.Printf("Identifier: %s\n", article.Identifier)
+		fmt.Printf("Address: %s\n", naddr)
+		fmt.Printf("View at: https://njump.me/%s\n", naddr)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func handleDeleteCommand(args []string) {
+	cmd := flag.NewFlagSet("delete", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	reason := cmd.String("reason", "", "Why the events are being deleted")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the publish report as JSON")
+
+	// Allow the event references before the flags, as in `nostr delete <note1> <note1> -reason ...`
+	refs := []string{}
+	for ref, rest := leadingArg(args); ref != ""; ref, rest = leadingArg(rest) {
+		refs = append(refs, ref)
+		args = rest
+	}
+	parseWithProfile(cmd, args, keys, false)
+	refs = append(refs, cmd.Args()...)
+	if len(refs) == 0 {
+		fmt.Println("Usage: nostr delete <note|nevent|hex id>... [-reason <text>]")
+		os.Exit(1)
+	}
+
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := client.DeleteEvents(ctx, refs, *reason)
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error deleting events: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		fmt.Printf("Deletion request for %d event(s) sent successfully!\n", len(refs))
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/nostr"
+)
+
+// handleFollowCommand dispatches the `nostr follow <subcommand>` family
+func handleFollowCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: nostr follow <add|remove|list> [flags]")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "add":
+		handleFollowAddCommand(args[1:])
+
+	case "remove":
+		handleFollowRemoveCommand(args[1:])
+
+	case "list":
+		handleFollowListCommand(args[1:])
+
+	default:
+		fmt.Printf("Unknown follow command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleFollowAddCommand(args []string) {
+	cmd := flag.NewFlagSet("follow add", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	relayHint := cmd.String("relay-hint", "", "Relay where the followed keys can be found")
+	petname := cmd.String("petname", "", "Local name for the followed key")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the publish report as JSON")
+	parseWithProfile(cmd, args, keys, false)
+
+	if cmd.NArg() == 0 {
+		fmt.Println("Usage: nostr follow add [flags] <npub|hex|nprofile>...")
+		os.Exit(1)
+	}
+
+	follows := []nostr.Follow{}
+	for _, pubKey := range cmd.Args() {
+		follows = append(follows, nostr.Follow{PubKey: pubKey, Relay: *relayHint, Petname: *petname})
+	}
+
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := client.Follow(ctx, follows)
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error updating follow list: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		fmt.Printf("Followed %d keys successfully!\n", len(follows))
+	}
+}
+
+func handleFollowRemoveCommand(args []string) {
+	cmd := flag.NewFlagSet("follow remove", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the publish report as JSON")
+	parseWithProfile(cmd, args, keys, false)
+
+	if cmd.NArg() == 0 {
+		fmt.Println("Usage: nostr follow remove [flags] <npub|hex|nprofile>...")
+		os.Exit(1)
+	}
+
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := client.Unfollow(ctx, cmd.Args())
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error updating follow list: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		fmt.Println("Unfollowed successfully!")
+	}
+}
+
+func handleFollowListCommand(args []string) {
+	cmd := flag.NewFlagSet("follow list", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the follow list as JSON")
+
+	// Allow the key before the flags, as in `nostr follow list <npub> -relays ...`
+	pubKey, args := leadingArg(args)
+	parseWithProfile(cmd, args, keys, false)
+	if pubKey == "" {
+		pubKey = cmd.Arg(0)
+	}
+
+	client := newReadClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	// Default to our own follows
+	if pubKey == "" {
+		pubKey = client.GetPublicKey()
+	}
+	if pubKey == "" {
+		fmt.Println("Usage: nostr follow list <npub> (or give a key to list your own follows)")
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	follows, err := client.ListFollows(ctx, pubKey)
+	if err != nil {
+		fmt.Printf("Error fetching follow list: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(follows, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(follows) == 0 {
+		fmt.Println("No follows found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NPUB\tPETNAME\tRELAY")
+	for _, follow := range follows {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", displayKey(follow.PubKey), follow.Petname, follow.Relay)
+	}
+	w.Flush()
+}
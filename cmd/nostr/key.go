@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/nostr"
+	"golang.org/x/term"
+)
+
+// handleKeyCommand dispatches the `nostr key <subcommand>` family
+func handleKeyCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: nostr key <generate|from-mnemonic|inspect|encrypt|decrypt> [flags]")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "generate":
+		handleKeyGenerateCommand(args[1:])
+
+	case "from-mnemonic":
+		handleKeyFromMnemonicCommand(args[1:])
+
+	case "inspect":
+		handleKeyInspectCommand(args[1:])
+
+	case "encrypt":
+		handleKeyEncryptCommand(args[1:])
+
+	case "decrypt":
+		handleKeyDecryptCommand(args[1:])
+
+	default:
+		fmt.Printf("Unknown key command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleKeyGenerateCommand(args []string) {
+	cmd := flag.NewFlagSet("key generate", flag.ExitOnError)
+	withMnemonic := cmd.Bool("mnemonic", false, "Generate a BIP-39 mnemonic and derive the key from it (NIP-06)")
+	cmd.Parse(args)
+
+	privateKey := nostr.GeneratePrivateKey()
+	if *withMnemonic {
+		mnemonic, err := nostr.GenerateMnemonic()
+		if err != nil {
+			fmt.Printf("Error generating mnemonic: %v\n", err)
+			os.Exit(1)
+		}
+
+		privateKey, err = nostr.PrivateKeyFromMnemonic(mnemonic, "", 0)
+		if err != nil {
+			fmt.Printf("Error deriving key: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("mnemonic: %s\n", mnemonic)
+	}
+
+	info, err := nostr.InspectKey(privateKey, nil, true)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	printKeyInfo(info)
+}
+
+func handleKeyFromMnemonicCommand(args []string) {
+	cmd := flag.NewFlagSet("key from-mnemonic", flag.ExitOnError)
+	mnemonic := cmd.String("mnemonic", "", "BIP-39 mnemonic phrase (read from stdin if empty)")
+	account := cmd.Uint("account", 0, "Account index in m/44'/1237'/<account>'/0/0")
+	bip39Passphrase := cmd.String("passphrase", "", "Optional BIP-39 passphrase")
+	cmd.Parse(args)
+
+	if *mnemonic == "" && cmd.NArg() > 0 {
+		*mnemonic = strings.Join(cmd.Args(), " ")
+	}
+	if *mnemonic == "" {
+		fmt.Fprint(os.Stderr, "Mnemonic: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Printf("Error reading mnemonic: %v\n", err)
+			os.Exit(1)
+		}
+		*mnemonic = strings.TrimSpace(line)
+	}
+
+	privateKey, err := nostr.PrivateKeyFromMnemonic(*mnemonic, *bip39Passphrase, uint32(*account))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	info, err := nostr.InspectKey(privateKey, nil, true)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("path:     m/44'/1237'/%d'/0/0\n", *account)
+	printKeyInfo(info)
+}
+
+func handleKeyInspectCommand(args []string) {
+	cmd := flag.NewFlagSet("key inspect", flag.ExitOnError)
+	relayURLs := cmd.String("relays", "", "Comma-separated relay hints for the nprofile")
+	hexIsPrivate := cmd.Bool("private", false, "Treat a bare hex key as a private key")
+	cmd.Parse(args)
+
+	if cmd.NArg() == 0 {
+		fmt.Println("Usage: nostr key inspect [-relays ...] [-private] <hex|nsec|npub|nprofile>")
+		os.Exit(1)
+	}
+
+	var relays []string
+	if *relayURLs != "" {
+		relays = parseRelayList(*relayURLs)
+	}
+
+	info, err := nostr.InspectKey(cmd.Arg(0), relays, *hexIsPrivate)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	printKeyInfo(info)
+}
+
+// printKeyInfo prints every known encoding of a key
+func printKeyInfo(info *nostr.KeyInfo) {
+	if info.PrivateKey != "" {
+		fmt.Printf("hex:      %s\n", info.PrivateKey)
+		fmt.Printf("nsec:     %s\n", info.Nsec)
+	}
+	fmt.Printf("pubkey:   %s\n", info.PublicKey)
+	fmt.Printf("npub:     %s\n", info.Npub)
+	fmt.Printf("nprofile: %s\n", info.Nprofile)
+}
+
+func handleKeyEncryptCommand(args []string) {
+	cmd := flag.NewFlagSet("key encrypt", flag.ExitOnError)
+	privateKeyHex := cmd.String("key", "", "Private key in hex format")
+	nsecKey := cmd.String("nsec", "", "Private key in nsec format")
+	logN := cmd.Uint("logn", nostr.DefaultScryptLogN, "scrypt work factor as a power of two (16-22)")
+	cmd.Parse(args)
+
+	// Check before asking for the passphrase; uint8 would also wrap values above 255
+	if *logN < nostr.MinScryptLogN || *logN > nostr.MaxScryptLogN {
+		fmt.Printf("Error: -logn %d: %v\n", *logN, nostr.ErrInvalidScryptLogN)
+		os.Exit(1)
+	}
+
+	privateKey, err := nostr.DeterminePrivateKey(*privateKeyHex, *nsecKey)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	passphrase, err := readPassphrase("Passphrase: ", true)
+	if err != nil {
+		fmt.Printf("Error reading passphrase: %v\n", err)
+		os.Exit(1)
+	}
+
+	ncryptsec, err := nostr.EncryptPrivateKey(privateKey, passphrase, uint8(*logN))
+	if err != nil {
+		fmt.Printf("Error encrypting key: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(ncryptsec)
+}
+
+func handleKeyDecryptCommand(args []string) {
+	cmd := flag.NewFlagSet("key decrypt", flag.ExitOnError)
+	ncryptsec := cmd.String("ncryptsec", "", "Encrypted private key (ncryptsec1...)")
+	cmd.Parse(args)
+
+	if *ncryptsec == "" && cmd.NArg() > 0 {
+		*ncryptsec = cmd.Arg(0)
+	}
+	if *ncryptsec == "" {
+		fmt.Println("Error: an ncryptsec key is required")
+		os.Exit(1)
+	}
+
+	privateKey, err := decryptKey(*ncryptsec)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	nsec, err := nostr.FormatPrivateKey(privateKey)
+	if err != nil {
+		fmt.Printf("Error encoding key: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("hex:  %s\n", privateKey)
+	fmt.Printf("nsec: %s\n", nsec)
+}
+
+// decryptKey decrypts an ncryptsec using NOSTR_PASSPHRASE or an interactive prompt
+func decryptKey(ncryptsec string) (string, error) {
+	passphrase, err := readPassphrase("Passphrase for encrypted key: ", false)
+	if err != nil {
+		return "", err
+	}
+
+	return nostr.DecryptPrivateKey(ncryptsec, passphrase)
+}
+
+// readPassphrase returns NOSTR_PASSPHRASE if set, otherwise prompts for one on the terminal
+func readPassphrase(prompt string, confirm bool) (string, error) {
+	if passphrase := os.Getenv("NOSTR_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		// Piped input, read a single line
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+
+	if confirm {
+		fmt.Fprint(os.Stderr, "Repeat passphrase: ")
+		repeated, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		if string(repeated) != string(passphrase) {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+
+	return string(passphrase), nil
+}
@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"mime"
 	"os"
+	"path/filepath"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/konstantinmds/nostr_demo_golang/internal/config"
 	"github.com/konstantinmds/nostr_demo_golang/internal/nostr"
 )
 
@@ -21,27 +26,41 @@ func main() {
 	)
 
 	// Post command flags
-	postPrivateKeyHex := cmdPost.String("key", os.Getenv("NOSTR_PRIVATE_KEY"), "Private key in hex format")
-	postNsecKey := cmdPost.String("nsec", os.Getenv("NOSTR_NSEC_KEY"), "Private key in nsec format")
+	postKeys := addKeyFlags(cmdPost, os.Getenv("NOSTR_PRIVATE_KEY"), os.Getenv("NOSTR_NSEC_KEY"), os.Getenv("NOSTR_BUNKER"))
 	postMessage := cmdPost.String("message", "Hello world!", "Message to post")
-	postRelayURL := cmdPost.String("relay", "wss://relay.damus.io", "Relay URL")
+	postRelayURL := cmdPost.String("relay", "wss://relay.damus.io", "Relay URL (comma-separated for several)")
 	postClientID := cmdPost.String("client", "nostr_demo_golang", "Client identifier")
-	postTags := cmdPost.String("tags", "", "Additional tags in format 'key1:value1,key2:value2'")
+	postTags := cmdPost.String("tags", "", "Additional tags as a JSON array of arrays, or 'key1:value1,key2:value2'")
+	postTag := &stringList{}
+	cmdPost.Var(postTag, "tag", "Additional tag as comma-separated fields or a JSON array, e.g. 'p,<npub>,wss://relay,mention' (repeatable)")
 	postTimeout := cmdPost.Duration("timeout", 5*time.Second, "Connection timeout")
+	postJSON := cmdPost.Bool("json", false, "Print the publish report as JSON")
+	postOutbox := cmdPost.Bool("outbox", true, "Also send to our NIP-65 write relays and the read relays of mentioned users")
+	postExpires := cmdPost.Duration("expires", 0, "Expire the post after this long (NIP-40), e.g. 24h")
+	postQuote := cmdPost.String("quote", "", "Quote an event (hex ID, note, or nevent) (NIP-18)")
 
 	// DM command flags
-	dmPrivateKeyHex := cmdDM.String("key", "", "Private key in hex format")
-	dmNsecKey := cmdDM.String("nsec", "", "Private key in nsec format")
+	dmKeys := addKeyFlags(cmdDM, "", "", "")
 	dmRecipient := cmdDM.String("to", "", "Recipient public key (hex, npub, or nprofile)")
 	dmMessage := cmdDM.String("message", "", "Message content to send")
-	dmRelayURL := cmdDM.String("relay", "wss://relay.damus.io", "Relay URL")
+	dmRelayURL := cmdDM.String("relay", "wss://relay.damus.io", "Relay URL (comma-separated for several)")
 	dmTimeout := cmdDM.Duration("timeout", 5*time.Second, "Timeout for relay operations")
 	dmClientID := cmdDM.String("client", "nostr_demo_golang", "Client identifier")
+	dmJSON := cmdDM.Bool("json", false, "Print the publish report as JSON")
+	dmExpires := cmdDM.Duration("expires", 0, "Expire the message after this long (NIP-40), e.g. 24h")
+	dmMode := cmdDM.String("mode", "nip04", "Transport: nip04, nip17, or auto (NIP-17 if the recipient publishes DM relays)")
+
+	// DM read command flags
+	dmReadCmd := flag.NewFlagSet("dm-read", flag.ExitOnError)
+	dmReadKeys := addKeyFlags(dmReadCmd, "", "", "")
+	dmReadWith := dmReadCmd.String("with", "", "Public key of the other party (hex, npub, or nprofile)")
+	dmReadRelayURLs := dmReadCmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	dmReadSince := dmReadCmd.Duration("since", 30*24*time.Hour, "How far back to look for messages")
+	dmReadTimeout := dmReadCmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
 
 	// NIP-17 Direct Message Command
 	nip17dmCmd := flag.NewFlagSet("nip17dm", flag.ExitOnError)
-	nip17dmPrivKeyHex := nip17dmCmd.String("key", "", "Private key in hex format")
-	nip17dmNsecKey := nip17dmCmd.String("nsec", "", "Private key in nsec format")
+	nip17dmKeys := addKeyFlags(nip17dmCmd, "", "", "")
 	nip17dmRecipients := nip17dmCmd.String("to", "", "Comma-separated list of recipient public keys")
 	nip17dmMessage := nip17dmCmd.String("message", "", "Message content to send")
 	nip17dmRelayURLs := nip17dmCmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
@@ -49,21 +68,34 @@ func main() {
 	nip17dmSubject := nip17dmCmd.String("subject", "", "Subject/title of conversation")
 	nip17dmTimeout := nip17dmCmd.Duration("timeout", 5*time.Second, "Timeout for relay operations")
 	nip17dmClientID := nip17dmCmd.String("client", "nostr_demo_golang", "Client identifier")
+	nip17dmJSON := nip17dmCmd.Bool("json", false, "Print the publish report as JSON")
+	nip17dmExpires := nip17dmCmd.Duration("expires", 0, "Expire the message and its gift wraps after this long (NIP-40), e.g. 24h")
+	nip17dmFile := nip17dmCmd.String("file", "", "Send this file encrypted as a kind 15 file message instead of a text message")
+	nip17dmUploadServer := nip17dmCmd.String("upload-server", os.Getenv("NOSTR_UPLOAD_SERVER"), "Blossom or NIP-96 server URL for -file")
+	nip17dmUploadType := nip17dmCmd.String("upload-type", "blossom", "Upload server type: blossom or nip96")
 
 	// NIP-17 Set Preferred Relays Command
 	nip17relaysCmd := flag.NewFlagSet("nip17relays", flag.ExitOnError)
-	nip17relaysPrivKeyHex := nip17relaysCmd.String("key", "", "Private key in hex format")
-	nip17relaysNsecKey := nip17relaysCmd.String("nsec", "", "Private key in nsec format")
+	nip17relaysKeys := addKeyFlags(nip17relaysCmd, "", "", "")
 	nip17relaysURLs := nip17relaysCmd.String("relays", "", "Comma-separated list of preferred relay URLs")
 	nip17relaysTimeout := nip17relaysCmd.Duration("timeout", 5*time.Second, "Timeout for relay operations")
+	nip17relaysJSON := nip17relaysCmd.Bool("json", false, "Print the publish report as JSON")
+
+	// NIP-17 Inbox Command
+	inboxCmd := flag.NewFlagSet("inbox", flag.ExitOnError)
+	inboxKeys := addKeyFlags(inboxCmd, "", "", "")
+	inboxRelayURLs := inboxCmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	inboxSince := inboxCmd.Duration("since", 7*24*time.Hour, "How far back to look for messages")
+	inboxTimeout := inboxCmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	inboxDownload := inboxCmd.String("download", "", "Download and decrypt file messages into this directory")
 
 	switch os.Args[1] {
 	case "post":
-		cmdPost.Parse(os.Args[2:])
-		handlePostCommand(postPrivateKeyHex, postNsecKey, postMessage, postRelayURL, postClientID, postTags, postTimeout)
+		parseWithProfile(cmdPost, os.Args[2:], postKeys, false)
+		handlePostCommand(postKeys, postMessage, postRelayURL, postClientID, postTags, postTag, postQuote, postOutbox, postExpires, postTimeout, postJSON)
 
 	case "dm":
-		cmdDM.Parse(os.Args[2:])
+		parseWithProfile(cmdDM, os.Args[2:], dmKeys, false)
 		if *dmRecipient == "" {
 			fmt.Println("Error: recipient is required for direct messages")
 			os.Exit(1)
@@ -72,15 +104,68 @@ func main() {
 			fmt.Println("Error: message is required for direct messages")
 			os.Exit(1)
 		}
-		handleDMCommand(dmPrivateKeyHex, dmNsecKey, dmRecipient, dmMessage, dmRelayURL, dmClientID, dmTimeout)
+		handleDMCommand(dmKeys, dmRecipient, dmMessage, dmRelayURL, dmClientID, dmMode, dmExpires, dmTimeout, dmJSON)
+
+	case "dm-read":
+		parseWithProfile(dmReadCmd, os.Args[2:], dmReadKeys, false)
+		if *dmReadWith == "" {
+			fmt.Println("Error: -with is required to read a conversation")
+			os.Exit(1)
+		}
+		handleDMReadCommand(dmReadKeys, dmReadWith, dmReadRelayURLs, dmReadSince, dmReadTimeout)
 
 	case "nip17dm":
-		nip17dmCmd.Parse(os.Args[2:])
-		handleNIP17DMCommand(nip17dmPrivKeyHex, nip17dmNsecKey, nip17dmRecipients, nip17dmMessage, nip17dmRelayURLs, nip17dmReplyTo, nip17dmSubject, nip17dmClientID, nip17dmTimeout)
+		parseWithProfile(nip17dmCmd, os.Args[2:], nip17dmKeys, true)
+		if *nip17dmFile != "" {
+			if *nip17dmMessage != "" {
+				fmt.Println("Error: use either -message or -file")
+				os.Exit(1)
+			}
+			handleNIP17FileCommand(nip17dmKeys, nip17dmRecipients, nip17dmFile, nip17dmUploadServer, nip17dmUploadType, nip17dmRelayURLs, nip17dmReplyTo, nip17dmSubject, nip17dmClientID, nip17dmExpires, nip17dmTimeout, nip17dmJSON)
+			return
+		}
+		handleNIP17DMCommand(nip17dmKeys, nip17dmRecipients, nip17dmMessage, nip17dmRelayURLs, nip17dmReplyTo, nip17dmSubject, nip17dmClientID, nip17dmExpires, nip17dmTimeout, nip17dmJSON)
 
 	case "nip17relays":
-		nip17relaysCmd.Parse(os.Args[2:])
-		handleNIP17RelaysCommand(nip17relaysPrivKeyHex, nip17relaysNsecKey, nip17relaysURLs, nip17relaysTimeout)
+		parseWithProfile(nip17relaysCmd, os.Args[2:], nip17relaysKeys, true)
+		handleNIP17RelaysCommand(nip17relaysKeys, nip17relaysURLs, nip17relaysTimeout, nip17relaysJSON)
+
+	case "inbox":
+		parseWithProfile(inboxCmd, os.Args[2:], inboxKeys, true)
+		handleInboxCommand(inboxKeys, inboxRelayURLs, inboxDownload, inboxSince, inboxTimeout)
+
+	case "key":
+		handleKeyCommand(os.Args[2:])
+
+	case "room":
+		handleRoomCommand(os.Args[2:])
+
+	case "profile":
+		handleProfileCommand(os.Args[2:])
+
+	case "follow":
+		handleFollowCommand(os.Args[2:])
+
+	case "relays":
+		handleRelaysCommand(os.Args[2:])
+
+	case "react":
+		handleReactCommand(os.Args[2:])
+
+	case "article":
+		handleArticleCommand(os.Args[2:])
+
+	case "delete":
+		handleDeleteCommand(os.Args[2:])
+
+	case "repost":
+		handleRepostCommand(os.Args[2:])
+
+	case "reply":
+		handleReplyCommand(os.Args[2:])
+
+	case "thread":
+		handleThreadCommand(os.Args[2:])
 
 	default:
 		fmt.Printf("Unknown command: %s\n", os.Args[1])
@@ -89,62 +174,260 @@ func main() {
 	}
 }
 
-func handlePostCommand(privateKeyHex, nsecKey, message, relayURL, clientID, tags *string, timeout *time.Duration) {
-	privateKey, err := nostr.DeterminePrivateKey(*privateKeyHex, *nsecKey)
+// keyFlags are the flags every subcommand uses to pick its signer
+type keyFlags struct {
+	privateKeyHex *string
+	nsecKey       *string
+	bunker        *string
+	profile       *string
+}
+
+// addKeyFlags registers -key, -nsec, -bunker and -profile on a subcommand's flag set
+func addKeyFlags(fs *flag.FlagSet, keyDefault, nsecDefault, bunkerDefault string) *keyFlags {
+	return &keyFlags{
+		privateKeyHex: fs.String("key", keyDefault, "Private key in hex format"),
+		nsecKey:       fs.String("nsec", nsecDefault, "Private key in nsec or ncryptsec format"),
+		bunker:        fs.String("bunker", bunkerDefault, "NIP-46 remote signer URI (bunker://...), used instead of -key/-nsec"),
+		profile:       fs.String("profile", os.Getenv("NOSTR_PROFILE"), "Named profile from the config file"),
+	}
+}
+
+// parseWithProfile parses a subcommand's flags and fills every flag not given on the
+// command line from the selected config profile. NIP-17 commands (dm set) prefer the
+// profile's DM relays for -relays.
+func parseWithProfile(fs *flag.FlagSet, args []string, keys *keyFlags, dm bool) {
+	fs.Parse(args)
+
+	path, err := config.DefaultPath()
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fmt.Printf("Error locating config file: %v\n", err)
 		os.Exit(1)
 	}
 
-	pubKey, err := nostr.GetPublicKeyFromPrivate(privateKey)
+	cfg, err := config.Load(path)
 	if err != nil {
-		fmt.Printf("Error getting public key: %v\n", err)
+		fmt.Printf("Error loading config: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Convert to bech32 for display
-	npub, err := nostr.FormatPublicKey(pubKey)
+	profile, err := cfg.Profile(*keys.profile)
 	if err != nil {
-		fmt.Printf("Warning: couldn't format public key: %v\n", err)
-	} else {
-		fmt.Printf("Using public key: %s\n", npub)
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if profile == nil {
+		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
-	defer cancel()
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
 
-	fmt.Printf("Sending post to %s...\n", *relayURL)
-	noteID, err := nostr.SendPublicPost(ctx, privateKey, *message, *relayURL, *clientID, *tags)
-	if err != nil {
-		fmt.Printf("Error sending post: %v\n", err)
-		os.Exit(1)
+	values := map[string]string{
+		"relay":  strings.Join(profile.Relays, ","),
+		"relays": strings.Join(profile.RelaysFor(dm), ","),
+		"client": profile.ClientID,
+
+		"upload-server": profile.UploadServer,
+		"upload-type":   profile.UploadType,
+	}
+
+	// A key given on the command line replaces the profile's key source entirely
+	if !explicit["key"] && !explicit["nsec"] && !explicit["bunker"] {
+		envKey := profile.PrivateKey()
+		switch {
+		case profile.Bunker != "":
+			values["bunker"] = profile.Bunker
+		case profile.Nsec != "":
+			values["nsec"] = profile.Nsec
+		case profile.Key != "":
+			values["key"] = profile.Key
+		case strings.HasPrefix(envKey, "nsec1") || nostr.IsEncryptedKey(envKey):
+			values["nsec"] = envKey
+		case envKey != "":
+			values["key"] = envKey
+		}
+
+		// Don't let environment defaults shadow the profile's choice
+		if values["bunker"] != "" || values["nsec"] != "" || values["key"] != "" {
+			*keys.privateKeyHex, *keys.nsecKey, *keys.bunker = "", "", ""
+		}
+	}
+
+	for name, value := range values {
+		if value == "" || explicit[name] || fs.Lookup(name) == nil {
+			continue
+		}
+		fs.Set(name, value)
+	}
+}
+
+// signer returns a remote signer when -bunker is set, otherwise an in-memory key signer
+func (k *keyFlags) signer(timeout time.Duration) (nostr.Signer, error) {
+	if *k.bunker != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		fmt.Println("Connecting to remote signer...")
+		return nostr.NewBunkerSigner(ctx, *k.bunker, os.Getenv("NOSTR_BUNKER_CLIENT_KEY"), func(authURL string) {
+			fmt.Printf("Remote signer requires authorization, open: %s\n", authURL)
+		})
+	}
+
+	if nostr.IsEncryptedKey(*k.nsecKey) {
+		privateKey, err := decryptKey(*k.nsecKey)
+		if err != nil {
+			return nil, err
+		}
+		return nostr.NewKeySigner(privateKey)
 	}
 
-	fmt.Println("Post sent successfully!")
-	fmt.Printf("Event ID: %s\n", noteID)
-	fmt.Printf("View at: https://njump.me/%s\n", noteID)
+	privateKey, err := nostr.DeterminePrivateKey(*k.privateKeyHex, *k.nsecKey)
+	if err != nil {
+		return nil, err
+	}
+	return nostr.NewKeySigner(privateKey)
 }
 
-func handleDMCommand(privateKeyHex, nsecKey, recipient, message, relayURL, clientID *string, timeout *time.Duration) {
-	privateKey, err := nostr.DeterminePrivateKey(*privateKeyHex, *nsecKey)
+// newClient resolves the signer and creates a client with the given relays in its pool
+func newClient(keys *keyFlags, relayURLs []string, timeout time.Duration) *nostr.Client {
+	signer, err := keys.signer(timeout)
 	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	pubKey, err := nostr.GetPublicKeyFromPrivate(privateKey)
+	client, err := nostr.NewClient(signer, timeout)
 	if err != nil {
-		fmt.Printf("Error getting public key: %v\n", err)
+		fmt.Printf("Error creating client: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Stop listening for bunker responses when the command closes the client
+	if bunker, ok := signer.(*nostr.BunkerSigner); ok {
+		client.OnClose(bunker.Close)
+	}
+
+	fmt.Printf("Using public key: %s\n", client.GetPublicKeyBech32())
+
+	for _, relayURL := range relayURLs {
+		client.AddRelay(relayURL)
+	}
+
+	return client
+}
+
+// newReadClient creates a client for commands that only fetch public data. Without a key it is read-only;
+// a key, when given, lets the command default to our own public key.
+func newReadClient(keys *keyFlags, relayURLs []string, timeout time.Duration) *nostr.Client {
+	if *keys.privateKeyHex != "" || *keys.nsecKey != "" || *keys.bunker != "" {
+		return newClient(keys, relayURLs, timeout)
+	}
+
+	client := nostr.NewReadOnlyClient(timeout)
+	for _, relayURL := range relayURLs {
+		client.AddRelay(relayURL)
+	}
+	return client
+}
+
+// parseRelayList splits a comma-separated relay list and exits if it is empty
+func parseRelayList(relayURLs string) []string {
+	relayList := splitList(relayURLs)
+	if len(relayList) == 0 {
+		fmt.Println("Error: No relay URLs specified")
 		os.Exit(1)
 	}
 
-	npub, err := nostr.FormatPublicKey(pubKey)
+	return relayList
+}
+
+// leadingArg splits off a positional argument given before the flags, since flag stops at the first non-flag
+func leadingArg(args []string) (string, []string) {
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		return args[0], args[1:]
+	}
+	return "", args
+}
+
+// stringList is a flag that can be given several times, collecting every value
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, " ")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func handlePostCommand(keys *keyFlags, message, relayURL, clientID, tags *string, tag *stringList, quote *string, outbox *bool, expires, timeout *time.Duration, jsonOutput *bool) {
+	// Malformed tags stop the post rather than being dropped
+	postTags, err := nostr.ParseTags(*tags)
 	if err != nil {
-		fmt.Printf("Warning: couldn't format public key: %v\n", err)
+		fmt.Printf("Error in -tags: %v\n", err)
+		os.Exit(1)
+	}
+	for _, spec := range *tag {
+		parsed, err := nostr.ParseTag(spec)
+		if err != nil {
+			fmt.Printf("Error in -tag: %v\n", err)
+			os.Exit(1)
+		}
+		postTags = append(postTags, parsed)
+	}
+
+	client := newClient(keys, parseRelayList(*relayURL), *timeout)
+	defer client.Close()
+	client.SetOutboxRouting(*outbox)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	fmt.Printf("Sending post to %s...\n", strings.Join(client.Relays(), ", "))
+	var report *nostr.PublishReport
+	if *quote != "" {
+		report, err = client.QuotePost(ctx, *message, *quote, *clientID, postTags, *expires)
 	} else {
-		fmt.Printf("Using public key: %s\n", npub)
+		report, err = client.SendPublicPost(ctx, *message, *clientID, postTags, *expires)
+	}
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error sending post: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		fmt.Println("Post sent successfully!")
+		fmt.Printf("Event ID: %s\n", report.NoteID())
+		fmt.Printf("View at: https://njump.me/%s\n", report.NoteID())
+	}
+}
+
+func handleDMCommand(keys *keyFlags, recipient, message, relayURL, clientID, mode *string, expires, timeout *time.Duration, jsonOutput *bool) {
+	dmMode, err := nostr.ParseDMMode(*mode)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
 	}
 
+	client := newClient(keys, parseRelayList(*relayURL), *timeout)
+	defer client.Close()
+
 	recipientHex, err := nostr.DecodePublicKey(*recipient)
 	if err != nil {
 		fmt.Printf("Error with recipient key: %v\n", err)
@@ -159,38 +442,62 @@ func handleDMCommand(privateKeyHex, nsecKey, recipient, message, relayURL, clien
 	defer cancel()
 
 	// Send the DM
-	fmt.Printf("Sending encrypted DM via %s...\n", *relayURL)
-	noteID, err := nostr.SendDirectMessage(ctx, privateKey, recipientHex, *message, *relayURL, *clientID)
+	fmt.Printf("Sending encrypted DM (%s) via %s...\n", dmMode, strings.Join(client.Relays(), ", "))
+	report, err := client.SendDM(ctx, recipientHex, *message, *clientID, dmMode, *expires)
+	printReport(report, *jsonOutput)
 	if err != nil {
 		fmt.Printf("Error sending DM: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Direct message sent successfully!")
-	fmt.Printf("Event ID: %s\n", noteID)
-	fmt.Printf("View at: https://njump.me/%s\n", noteID)
+	if !*jsonOutput {
+		fmt.Println("Direct message sent successfully!")
+		fmt.Printf("Transport: %s\n", report.Transport)
+		fmt.Printf("Event ID: %s\n", report.NoteID())
+		fmt.Printf("View at: https://njump.me/%s\n", report.NoteID())
+	}
 }
 
-func handleNIP17DMCommand(privateKeyHex, nsecKey, recipients, message, relayURLs, replyToID, subject, clientID *string, timeout *time.Duration) {
-	privateKey, err := nostr.DeterminePrivateKey(*privateKeyHex, *nsecKey)
+func handleDMReadCommand(keys *keyFlags, with, relayURLs *string, since, timeout *time.Duration) {
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	pubKey := client.GetPublicKey()
+
+	peerHex, err := nostr.DecodePublicKey(*with)
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+		fmt.Printf("Error with peer key: %v\n", err)
 		os.Exit(1)
 	}
 
-	pubKey, err := nostr.GetPublicKeyFromPrivate(privateKey)
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	fmt.Printf("Fetching direct messages with %s...\n", displayKey(peerHex))
+	messages, err := client.FetchDirectMessages(ctx, peerHex, time.Now().Add(-*since))
 	if err != nil {
-		fmt.Printf("Error getting public key: %v\n", err)
+		fmt.Printf("Error fetching direct messages: %v\n", err)
 		os.Exit(1)
 	}
 
-	npub, err := nostr.FormatPublicKey(pubKey)
-	if err != nil {
-		fmt.Printf("Warning: couldn't format public key: %v\n", err)
-	} else {
-		fmt.Printf("Using public key: %s\n", npub)
+	for _, msg := range messages {
+		author := displayKey(msg.Sender)
+		if msg.Sender == pubKey {
+			author = "me"
+		}
+		fmt.Printf("[%s] %s: %s\n", msg.CreatedAt.Time().Format("2006-01-02 15:04:05"), author, msg.Content)
 	}
 
+	if len(messages) == 0 {
+		fmt.Println("No messages found.")
+	}
+}
+
+func handleNIP17DMCommand(keys *keyFlags, recipients, message, relayURLs, replyToID, subject, clientID *string, expires, timeout *time.Duration, jsonOutput *bool) {
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
 	// Parse recipient list
 	recipientList := strings.Split(*recipients, ",")
 	if len(recipientList) == 0 || (len(recipientList) == 1 && recipientList[0] == "") {
@@ -198,13 +505,6 @@ func handleNIP17DMCommand(privateKeyHex, nsecKey, recipients, message, relayURLs
 		os.Exit(1)
 	}
 
-	// Parse relay URLs
-	relayList := strings.Split(*relayURLs, ",")
-	if len(relayList) == 0 || (len(relayList) == 1 && relayList[0] == "") {
-		fmt.Println("Error: No relay URLs specified")
-		os.Exit(1)
-	}
-
 	// Display info about the recipients
 	for i, recipient := range recipientList {
 		recipientHex, err := nostr.DecodePublicKey(recipient)
@@ -222,45 +522,65 @@ func handleNIP17DMCommand(privateKeyHex, nsecKey, recipients, message, relayURLs
 	defer cancel()
 
 	// Send the NIP-17 DM
-	fmt.Printf("Sending NIP-17 encrypted DM via %d relays...\n", len(relayList))
-	noteID, err := nostr.SendNIP17DirectMessage(ctx, privateKey, recipientList, *message, relayList, *replyToID, *subject, *clientID)
+	fmt.Printf("Sending NIP-17 encrypted DM via %d relays...\n", len(client.Relays()))
+	report, err := client.SendNIP17DirectMessage(ctx, recipientList, *message, *replyToID, *subject, *clientID, *expires)
+	printReport(report, *jsonOutput)
 	if err != nil {
 		fmt.Printf("Error sending NIP-17 DM: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("NIP-17 direct message sent successfully!")
-	fmt.Printf("Event ID: %s\n", noteID)
-	fmt.Printf("View at: https://njump.me/%s\n", noteID)
+	if !*jsonOutput {
+		fmt.Println("NIP-17 direct message sent successfully!")
+		fmt.Printf("Event ID: %s\n", report.NoteID())
+		fmt.Printf("View at: https://njump.me/%s\n", report.NoteID())
+	}
 }
 
-func handleNIP17RelaysCommand(privateKeyHex, nsecKey, relayURLs *string, timeout *time.Duration) {
-	privateKey, err := nostr.DeterminePrivateKey(*privateKeyHex, *nsecKey)
-	if err != nil {
-		fmt.Printf("Error: %v\n", err)
+func handleNIP17FileCommand(keys *keyFlags, recipients, filePath, uploadServer, uploadType, relayURLs, replyToID, subject, clientID *string, expires, timeout *time.Duration, jsonOutput *bool) {
+	if *uploadServer == "" {
+		fmt.Println("Error: -upload-server (or NOSTR_UPLOAD_SERVER) is required to send a file")
 		os.Exit(1)
 	}
 
-	pubKey, err := nostr.GetPublicKeyFromPrivate(privateKey)
-	if err != nil {
-		fmt.Printf("Error getting public key: %v\n", err)
+	recipientList := splitList(*recipients)
+	if len(recipientList) == 0 {
+		fmt.Println("Error: No recipients specified")
 		os.Exit(1)
 	}
 
-	npub, err := nostr.FormatPublicKey(pubKey)
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	uploader, err := nostr.NewFileUploader(*uploadType, *uploadServer, client.Signer())
 	if err != nil {
-		fmt.Printf("Warning: couldn't format public key: %v\n", err)
-	} else {
-		fmt.Printf("Using public key: %s\n", npub)
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
 	}
 
-	// Parse relay URLs
-	relayList := strings.Split(*relayURLs, ",")
-	if len(relayList) == 0 || (len(relayList) == 1 && relayList[0] == "") {
-		fmt.Println("Error: No relay URLs specified")
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	fmt.Printf("Encrypting %s and uploading it to %s...\n", *filePath, *uploadServer)
+	report, err := client.SendNIP17File(ctx, recipientList, *filePath, uploader, *replyToID, *subject, *clientID, *expires)
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error sending NIP-17 file: %v\n", err)
 		os.Exit(1)
 	}
 
+	if !*jsonOutput {
+		fmt.Println("NIP-17 file message sent successfully!")
+	}
+}
+
+func handleNIP17RelaysCommand(keys *keyFlags, relayURLs *string, timeout *time.Duration, jsonOutput *bool) {
+	relayList := parseRelayList(*relayURLs)
+
+	client := newClient(keys, nil, *timeout)
+	defer client.Close()
+
 	fmt.Printf("Setting NIP-17 preferred relays: %s\n", *relayURLs)
 
 	// Create context with timeout
@@ -268,13 +588,130 @@ func handleNIP17RelaysCommand(privateKeyHex, nsecKey, relayURLs *string, timeout
 	defer cancel()
 
 	// Publish NIP-17 preferences
-	noteID, err := nostr.PublishNIP17Preferences(ctx, privateKey, relayList)
+	report, err := client.PublishNIP17Preferences(ctx, relayList)
+	printReport(report, *jsonOutput)
 	if err != nil {
 		fmt.Printf("Error publishing NIP-17 preferences: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("NIP-17 relay preferences published successfully!")
-	fmt.Printf("Event ID: %s\n", noteID)
-	fmt.Printf("View at: https://njump.me/%s\n", noteID)
+	if !*jsonOutput {
+		fmt.Println("NIP-17 relay preferences published successfully!")
+		fmt.Printf("Event ID: %s\n", report.NoteID())
+		fmt.Printf("View at: https://njump.me/%s\n", report.NoteID())
+	}
+}
+
+func handleInboxCommand(keys *keyFlags, relayURLs, downloadDir *string, since, timeout *time.Duration) {
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	pubKey := client.GetPublicKey()
+
+	// Create context with timeout
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	fmt.Printf("Fetching NIP-17 messages from %d relays...\n", len(client.Relays()))
+	messages, err := client.FetchNIP17Messages(ctx, time.Now().Add(-*since))
+	if err != nil {
+		fmt.Printf("Error fetching NIP-17 messages: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(messages) == 0 {
+		fmt.Println("No messages found.")
+		return
+	}
+
+	for _, room := range nostr.GroupRooms(messages) {
+		fmt.Printf("\n=== Conversation with %s (room %s) ===\n", roomPeers(room, pubKey), room.ID)
+		printRoomMessages(ctx, room, pubKey, *downloadDir)
+	}
+}
+
+// printReport prints where each event landed, either as a table or as JSON
+func printReport(report *nostr.PublishReport, jsonOutput bool) {
+	if report == nil {
+		return
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Printf("Error encoding report: %v\n", err)
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "EVENT\tRECIPIENT\tRELAY\tSTATUS\tLATENCY\tMESSAGE")
+	for _, event := range report.Events {
+		recipient := "-"
+		if event.Recipient != "" {
+			recipient = shortKey(displayKey(event.Recipient))
+		}
+		for _, result := range event.Results {
+			status := "rejected"
+			if result.Accepted {
+				status = "accepted"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", shortKey(event.EventID), recipient, result.Relay,
+				status, result.Latency.Round(time.Millisecond), result.Message)
+		}
+	}
+	w.Flush()
+
+	for _, warning := range report.Warnings {
+		fmt.Printf("Warning: %s\n", warning)
+	}
+}
+
+// downloadFile fetches and decrypts a file message, saving it under dir named by its hash,
+// or by the message ID when the sender gave no hash
+func downloadFile(ctx context.Context, msg *nostr.NIP17Message, dir string) {
+	file := msg.File
+	data, err := file.Download(ctx)
+	if err != nil {
+		fmt.Printf("    Error downloading file: %v\n", err)
+		return
+	}
+
+	name := file.OriginalHash
+	if name == "" {
+		name = file.Hash
+	}
+	if name == "" {
+		name = msg.ID
+	}
+	if exts, _ := mime.ExtensionsByType(file.Type); len(exts) > 0 {
+		name += exts[0]
+	}
+
+	// The name comes from the sender, never let it leave dir
+	path := filepath.Join(dir, filepath.Base(name))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		fmt.Printf("    Error saving file: %v\n", err)
+		return
+	}
+	fmt.Printf("    Saved to %s\n", path)
+}
+
+// shortKey abbreviates long identifiers for table output
+func shortKey(key string) string {
+	if len(key) <= 16 {
+		return key
+	}
+	return key[:10] + "…" + key[len(key)-4:]
+}
+
+// displayKey formats a hex public key as npub, falling back to hex
+func displayKey(pubKeyHex string) string {
+	npub, err := nostr.FormatPublicKey(pubKeyHex)
+	if err != nil {
+		return pubKeyHex
+	}
+	return npub
 }
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/config"
+)
+
+// writeConfig writes cfg to a temporary file and points NOSTR_CONFIG at it
+func writeConfig(t *testing.T, cfg *config.Config) {
+	t.Helper()
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NOSTR_CONFIG", path)
+	t.Setenv("NOSTR_PROFILE", "")
+}
+
+func TestParseWithProfile(t *testing.T) {
+	cfg := &config.Config{
+		DefaultProfile: "work",
+		Profiles: map[string]*config.Profile{
+			"work": {
+				Nsec:     "nsec-from-profile",
+				Relays:   []string{"wss://work.example"},
+				DMRelays: []string{"wss://dm.example"},
+				ClientID: "work-client",
+			},
+			"relays-only": {Relays: []string{"wss://relays-only.example"}},
+			"env-key":     {KeyEnv: "TEST_PROFILE_KEY"},
+		},
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		dm   bool
+		// keyDefault stands in for the environment default the command registers for -key
+		keyDefault string
+		want       map[string]string
+	}{
+		{
+			name: "profile fills unset flags",
+			args: nil,
+			want: map[string]string{"relay": "wss://work.example", "client": "work-client", "nsec": "nsec-from-profile"},
+		},
+		{
+			name: "flag beats profile",
+			args: []string{"-relay", "wss://flag.example", "-client", "flag-client"},
+			want: map[string]string{"relay": "wss://flag.example", "client": "flag-client"},
+		},
+		{
+			name:       "profile key beats env default",
+			keyDefault: "key-from-env",
+			want:       map[string]string{"key": "", "nsec": "nsec-from-profile"},
+		},
+		{
+			name:       "key flag replaces the profile's key source",
+			args:       []string{"-key", "key-from-flag"},
+			keyDefault: "key-from-env",
+			want:       map[string]string{"key": "key-from-flag", "nsec": ""},
+		},
+		{
+			name:       "profile without a key keeps env default",
+			args:       []string{"-profile", "relays-only"},
+			keyDefault: "key-from-env",
+			want:       map[string]string{"key": "key-from-env", "relay": "wss://relays-only.example"},
+		},
+		{
+			name: "key_env is read from the environment",
+			args: []string{"-profile", "env-key"},
+			want: map[string]string{"key": "key-from-key-env", "nsec": ""},
+		},
+		{
+			name: "dm commands prefer DM relays",
+			dm:   true,
+			want: map[string]string{"relay": "wss://work.example", "relays": "wss://dm.example"},
+		},
+		{
+			name: "general relays otherwise",
+			want: map[string]string{"relays": "wss://work.example"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, cfg)
+			t.Setenv("TEST_PROFILE_KEY", "key-from-key-env")
+
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.String("relay", "", "")
+			fs.String("relays", "", "")
+			fs.String("client", "", "")
+			keys := addKeyFlags(fs, tt.keyDefault, "", "")
+
+			parseWithProfile(fs, tt.args, keys, tt.dm)
+
+			for name, want := range tt.want {
+				if got := fs.Lookup(name).Value.String(); got != want {
+					t.Errorf("-%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseWithProfileWithoutConfig(t *testing.T) {
+	t.Setenv("NOSTR_CONFIG", filepath.Join(t.TempDir(), "missing.json"))
+	t.Setenv("NOSTR_PROFILE", "")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	relay := fs.String("relay", "wss://default.example", "")
+	keys := addKeyFlags(fs, "key-from-env", "", "")
+
+	parseWithProfile(fs, nil, keys, false)
+
+	// With no config file nothing changes
+	if *relay != "wss://default.example" || *keys.privateKeyHex != "key-from-env" {
+		t.Errorf("relay = %q, key = %q, want the defaults", *relay, *keys.privateKeyHex)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/nostr"
+)
+
+// handleProfileCommand dispatches the `nostr profile <subcommand>` family
+func handleProfileCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: nostr profile <get|set> [flags]")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "get":
+		handleProfileGetCommand(args[1:])
+
+	case "set":
+		handleProfileSetCommand(args[1:])
+
+	default:
+		fmt.Printf("Unknown profile command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleProfileGetCommand(args []string) {
+	cmd := flag.NewFlagSet("profile get", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the profile as JSON")
+
+	// Allow the key before the flags, as in `nostr profile get <npub> -relays ...`
+	pubKey, args := leadingArg(args)
+	parseWithProfile(cmd, args, keys, false)
+	if pubKey == "" {
+		pubKey = cmd.Arg(0)
+	}
+
+	client := newReadClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	// Default to our own profile
+	if pubKey == "" {
+		pubKey = client.GetPublicKey()
+	}
+	if pubKey == "" {
+		fmt.Println("Usage: nostr profile get <npub> (or give a key to show your own profile)")
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	profile, err := client.GetProfile(ctx, pubKey)
+	if err != nil {
+		fmt.Printf("Error fetching profile: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		// Everything the text output shows, including where the metadata came from
+		data, _ := json.MarshalIndent(struct {
+			PubKey    string `json:"pubkey"`
+			Npub      string `json:"npub"`
+			CreatedAt int64  `json:"created_at"`
+			*nostr.ProfileMetadata
+		}{profile.PubKey, displayKey(profile.PubKey), int64(profile.CreatedAt), profile}, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("npub:         %s\n", displayKey(profile.PubKey))
+	fmt.Printf("updated:      %s\n", profile.CreatedAt.Time().Format("2006-01-02 15:04:05"))
+	fmt.Printf("name:         %s\n", profile.Name)
+	fmt.Printf("display_name: %s\n", profile.DisplayName)
+	fmt.Printf("about:        %s\n", profile.About)
+	fmt.Printf("picture:      %s\n", profile.Picture)
+	fmt.Printf("banner:       %s\n", profile.Banner)
+	fmt.Printf("nip05:        %s\n", profile.NIP05)
+	fmt.Printf("lud16:        %s\n", profile.LUD16)
+	fmt.Printf("website:      %s\n", profile.Website)
+}
+
+func handleProfileSetCommand(args []string) {
+	cmd := flag.NewFlagSet("profile set", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the publish report as JSON")
+
+	update := &nostr.ProfileMetadata{}
+	cmd.StringVar(&update.Name, "name", "", "Name")
+	cmd.StringVar(&update.DisplayName, "display-name", "", "Display name")
+	cmd.StringVar(&update.About, "about", "", "About text")
+	cmd.StringVar(&update.Picture, "picture", "", "Picture URL")
+	cmd.StringVar(&update.Banner, "banner", "", "Banner URL")
+	cmd.StringVar(&update.NIP05, "nip05", "", "NIP-05 identifier, e.g. bot@example.com")
+	cmd.StringVar(&update.LUD16, "lud16", "", "Lightning address")
+	cmd.StringVar(&update.Website, "website", "", "Website URL")
+	parseWithProfile(cmd, args, keys, false)
+
+	if *update == (nostr.ProfileMetadata{}) {
+		fmt.Println("Error: set at least one profile field, e.g. -name or -about")
+		os.Exit(1)
+	}
+
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := client.SetProfile(ctx, update)
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error setting profile: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		fmt.Println("Profile updated successfully!")
+	}
+}
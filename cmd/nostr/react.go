@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/nostr"
+)
+
+func handleReactCommand(args []string) {
+	cmd := flag.NewFlagSet("react", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	emoji := cmd.String("emoji", "+", "Reaction: +, -, an emoji, or the shortcode of a custom emoji given with -emoji-url")
+	emojiURL := cmd.String("emoji-url", "", "Image URL of a custom emoji (NIP-30), making -emoji its shortcode")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the publish report as JSON")
+
+	// Allow the event reference before the flags, as in `nostr react <nevent> -emoji 🤙`
+	eventRef, args := leadingArg(args)
+	parseWithProfile(cmd, args, keys, false)
+	if eventRef == "" {
+		eventRef = cmd.Arg(0)
+	}
+	if eventRef == "" {
+		fmt.Println("Usage: nostr react <note|nevent|hex id> [-emoji 🤙]")
+		os.Exit(1)
+	}
+
+	// With an image URL the reaction is a custom emoji, sent as :shortcode: whether or not
+	// the colons were typed
+	content := *emoji
+	var emojis []nostr.CustomEmoji
+	if *emojiURL != "" {
+		shortcode := strings.Trim(*emoji, ":")
+		content = ":" + shortcode + ":"
+		emojis = append(emojis, nostr.CustomEmoji{Shortcode: shortcode, URL: *emojiURL})
+	}
+
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := client.React(ctx, eventRef, content, emojis...)
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error reacting: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		fmt.Println("Reaction sent successfully!")
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/nostr"
+)
+
+// handleRelaysCommand dispatches the `nostr relays <subcommand>` family for NIP-65 relay lists
+func handleRelaysCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: nostr relays <get|set> [flags]")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "get":
+		handleRelaysGetCommand(args[1:])
+
+	case "set":
+		handleRelaysSetCommand(args[1:])
+
+	default:
+		fmt.Printf("Unknown relays command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+func handleRelaysGetCommand(args []string) {
+	cmd := flag.NewFlagSet("relays get", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs to search")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the relay list as JSON")
+
+	// Allow the key before the flags, as in `nostr relays get <npub> -relays ...`
+	pubKey, args := leadingArg(args)
+	parseWithProfile(cmd, args, keys, false)
+	if pubKey == "" {
+		pubKey = cmd.Arg(0)
+	}
+
+	client := newReadClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	// Default to our own relay list
+	if pubKey == "" {
+		pubKey = client.GetPublicKey()
+	}
+	if pubKey == "" {
+		fmt.Println("Usage: nostr relays get <npub> (or give a key to show your own relay list)")
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	list, err := client.FetchRelayList(ctx, pubKey)
+	if err != nil {
+		fmt.Printf("Error fetching relay list: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		data, _ := json.MarshalIndent(list, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("Relay list of %s, updated %s\n", displayKey(list.PubKey), list.CreatedAt.Time().Format("2006-01-02 15:04:05"))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RELAY\tREAD\tWRITE")
+	for _, entry := range list.Entries {
+		fmt.Fprintf(w, "%s\t%t\t%t\n", entry.URL, entry.Read, entry.Write)
+	}
+	w.Flush()
+}
+
+func handleRelaysSetCommand(args []string) {
+	cmd := flag.NewFlagSet("relays set", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs to publish to")
+	both := cmd.String("both", "", "Comma-separated relays used for reading and writing")
+	read := cmd.String("read", "", "Comma-separated relays we read mentions from")
+	write := cmd.String("write", "", "Comma-separated relays we publish to")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the publish report as JSON")
+	parseWithProfile(cmd, args, keys, false)
+
+	entries := relayListEntries(*both, *read, *write)
+	if len(entries) == 0 {
+		fmt.Println("Error: at least one of -both, -read or -write is required")
+		os.Exit(1)
+	}
+
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := client.PublishRelayList(ctx, entries)
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error publishing relay list: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		fmt.Println("Relay list published successfully!")
+	}
+}
+
+// relayListEntries builds the relay list from the -both, -read and -write flags. A relay named
+// in more than one of them gets a single entry with every direction it was given.
+func relayListEntries(both, read, write string) []nostr.RelayListEntry {
+	entries := []nostr.RelayListEntry{}
+	index := map[string]int{}
+
+	add := func(list string, read, write bool) {
+		for _, relayURL := range splitList(list) {
+			i, ok := index[relayURL]
+			if !ok {
+				i = len(entries)
+				index[relayURL] = i
+				entries = append(entries, nostr.RelayListEntry{URL: relayURL})
+			}
+			entries[i].Read = entries[i].Read || read
+			entries[i].Write = entries[i].Write || write
+		}
+	}
+	add(both, true, true)
+	add(read, true, false)
+	add(write, false, true)
+
+	return entries
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/nostr"
+)
+
+func TestRelayListEntries(t *testing.T) {
+	tests := []struct {
+		name              string
+		both, read, write string
+		want              []nostr.RelayListEntry
+	}{
+		{
+			name:  "one direction each",
+			read:  "wss://a.example",
+			write: "wss://b.example",
+			want:  []nostr.RelayListEntry{{URL: "wss://a.example", Read: true}, {URL: "wss://b.example", Write: true}},
+		},
+		{
+			name:  "read and write is one unmarked entry",
+			read:  "wss://a.example, wss://b.example",
+			write: "wss://a.example",
+			want:  []nostr.RelayListEntry{{URL: "wss://a.example", Read: true, Write: true}, {URL: "wss://b.example", Read: true}},
+		},
+		{
+			name: "both absorbs a repeat",
+			both: "wss://a.example",
+			read: "wss://a.example",
+			want: []nostr.RelayListEntry{{URL: "wss://a.example", Read: true, Write: true}},
+		},
+		{
+			name: "nothing given",
+			want: []nostr.RelayListEntry{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := relayListEntries(tt.both, tt.read, tt.write); !slices.Equal(got, tt.want) {
+				t.Errorf("relayListEntries = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
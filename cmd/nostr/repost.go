@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+func handleRepostCommand(args []string) {
+	cmd := flag.NewFlagSet("repost", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the publish report as JSON")
+
+	// Allow the event reference before the flags, as in `nostr repost <nevent> -relays ...`
+	eventRef, args := leadingArg(args)
+	parseWithProfile(cmd, args, keys, false)
+	if eventRef == "" {
+		eventRef = cmd.Arg(0)
+	}
+	if eventRef == "" {
+		fmt.Println("Usage: nostr repost <note|nevent|hex id>")
+		os.Exit(1)
+	}
+
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := client.Repost(ctx, eventRef)
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error reposting: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		fmt.Println("Repost sent successfully!")
+		fmt.Printf("Event ID: %s\n", report.NoteID())
+	}
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/nostr"
+)
+
+// roomFlags are the flags shared by every room subcommand
+type roomFlags struct {
+	keys      *keyFlags
+	relayURLs *string
+	since     *time.Duration
+	timeout   *time.Duration
+}
+
+// addRoomFlags registers the key, relay, since and timeout flags on a room subcommand
+func addRoomFlags(fs *flag.FlagSet) *roomFlags {
+	return &roomFlags{
+		keys:      addKeyFlags(fs, "", "", ""),
+		relayURLs: fs.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs"),
+		since:     fs.Duration("since", 30*24*time.Hour, "How far back to look for messages"),
+		timeout:   fs.Duration("timeout", 10*time.Second, "Timeout for relay operations"),
+	}
+}
+
+// handleRoomCommand dispatches the `nostr room <subcommand>` family
+func handleRoomCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Usage: nostr room <list|show|send> [flags]")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "list":
+		handleRoomListCommand(args[1:])
+
+	case "show":
+		handleRoomShowCommand(args[1:])
+
+	case "send":
+		handleRoomSendCommand(args[1:])
+
+	default:
+		fmt.Printf("Unknown room command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// fetchRooms returns our inbox grouped into rooms, exiting on error
+func fetchRooms(ctx context.Context, client *nostr.Client, since time.Duration) []*nostr.Room {
+	fmt.Printf("Fetching NIP-17 messages from %d relays...\n", len(client.Relays()))
+	rooms, err := client.FetchRooms(ctx, time.Now().Add(-since))
+	if err != nil {
+		fmt.Printf("Error fetching NIP-17 messages: %v\n", err)
+		os.Exit(1)
+	}
+	return rooms
+}
+
+func handleRoomListCommand(args []string) {
+	cmd := flag.NewFlagSet("room list", flag.ExitOnError)
+	flags := addRoomFlags(cmd)
+	parseWithProfile(cmd, args, flags.keys, true)
+
+	client := newClient(flags.keys, parseRelayList(*flags.relayURLs), *flags.timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *flags.timeout)
+	defer cancel()
+
+	rooms := fetchRooms(ctx, client, *flags.since)
+	if len(rooms) == 0 {
+		fmt.Println("No rooms found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROOM\tLAST ACTIVITY\tMESSAGES\tSUBJECT\tPARTICIPANTS")
+	for _, room := range rooms {
+		others := []string{}
+		for _, participant := range room.Others(client.GetPublicKey()) {
+			others = append(others, shortKey(displayKey(participant)))
+		}
+		if len(others) == 0 {
+			others = append(others, "(note to self)")
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", room.ID, room.LastActivity.Time().Format("2006-01-02 15:04"),
+			len(room.Messages), room.Subject, strings.Join(others, ", "))
+	}
+	w.Flush()
+}
+
+func handleRoomShowCommand(args []string) {
+	cmd := flag.NewFlagSet("room show", flag.ExitOnError)
+	flags := addRoomFlags(cmd)
+	downloadDir := cmd.String("download", "", "Download and decrypt file messages into this directory")
+	parseWithProfile(cmd, args, flags.keys, true)
+
+	if cmd.NArg() == 0 {
+		fmt.Println("Usage: nostr room show [flags] <room-id>")
+		os.Exit(1)
+	}
+
+	client := newClient(flags.keys, parseRelayList(*flags.relayURLs), *flags.timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *flags.timeout)
+	defer cancel()
+
+	room, err := nostr.FindRoom(fetchRooms(ctx, client, *flags.since), cmd.Arg(0))
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	pubKey := client.GetPublicKey()
+	fmt.Printf("\n=== Room %s with %s ===\n", room.ID, roomPeers(room, pubKey))
+	if room.Subject != "" {
+		fmt.Printf("Current subject: %s\n", room.Subject)
+	}
+	printRoomMessages(ctx, room, pubKey, *downloadDir)
+}
+
+func handleRoomSendCommand(args []string) {
+	cmd := flag.NewFlagSet("room send", flag.ExitOnError)
+	flags := addRoomFlags(cmd)
+	roomID := cmd.String("room", "", "ID (or prefix) of an existing room to send into")
+	participants := cmd.String("to", "", "Comma-separated participant keys, to start or address a room without looking it up")
+	message := cmd.String("message", "", "Message content to send")
+	subject := cmd.String("subject", "", "Set a new subject for the room")
+	replyToID := cmd.String("reply-to", "", "Event ID to reply to")
+	clientID := cmd.String("client", "nostr_demo_golang", "Client identifier")
+	expires := cmd.Duration("expires", 0, "Expire the message and its gift wraps after this long (NIP-40), e.g. 24h")
+	jsonOutput := cmd.Bool("json", false, "Print the publish report as JSON")
+	parseWithProfile(cmd, args, flags.keys, true)
+
+	if (*roomID == "") == (*participants == "") {
+		fmt.Println("Error: exactly one of -room or -to is required")
+		os.Exit(1)
+	}
+	if *message == "" {
+		fmt.Println("Error: message is required")
+		os.Exit(1)
+	}
+
+	client := newClient(flags.keys, parseRelayList(*flags.relayURLs), *flags.timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *flags.timeout)
+	defer cancel()
+
+	var room *nostr.Room
+	var err error
+	if *roomID != "" {
+		room, err = nostr.FindRoom(fetchRooms(ctx, client, *flags.since), *roomID)
+	} else {
+		room, err = client.NewRoom(splitList(*participants))
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Sending to room %s with %s...\n", room.ID, roomPeers(room, client.GetPublicKey()))
+	report, err := client.SendToRoom(ctx, room, *message, *replyToID, *subject, *clientID, *expires)
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error sending to room: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		fmt.Println("Message sent to room successfully!")
+	}
+}
+
+// roomPeers lists a room's other participants for display
+func roomPeers(room *nostr.Room, pubKey string) string {
+	peers := []string{}
+	for _, participant := range room.Others(pubKey) {
+		peers = append(peers, displayKey(participant))
+	}
+	if len(peers) == 0 {
+		return "(note to self)"
+	}
+	return strings.Join(peers, ", ")
+}
+
+// printRoomMessages prints a room's history, noting each subject change
+func printRoomMessages(ctx context.Context, room *nostr.Room, pubKey, downloadDir string) {
+	subject := ""
+	for _, msg := range room.Messages {
+		if msg.Subject != "" && msg.Subject != subject {
+			subject = msg.Subject
+			fmt.Printf("  Subject: %s\n", subject)
+		}
+		author := displayKey(msg.Sender)
+		if msg.Sender == pubKey {
+			author = "me"
+		}
+		if msg.File != nil {
+			fmt.Printf("  [%s] %s: [file %s, %d bytes] %s\n", msg.CreatedAt.Time().Format("2006-01-02 15:04:05"), author,
+				msg.File.Type, msg.File.Size, msg.File.URL)
+			if downloadDir != "" {
+				downloadFile(ctx, msg, downloadDir)
+			}
+			continue
+		}
+		fmt.Printf("  [%s] %s: %s\n", msg.CreatedAt.Time().Format("2006-01-02 15:04:05"), author, msg.Content)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/nostr"
+)
+
+func handleReplyCommand(args []string) {
+	cmd := flag.NewFlagSet("reply", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	message := cmd.String("message", "", "Reply content")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+	jsonOutput := cmd.Bool("json", false, "Print the publish report as JSON")
+
+	// Allow the parent reference before the flags, as in `nostr reply <nevent> -message ...`
+	parentRef, args := leadingArg(args)
+	parseWithProfile(cmd, args, keys, false)
+	if parentRef == "" {
+		parentRef = cmd.Arg(0)
+	}
+	if parentRef == "" || *message == "" {
+		fmt.Println("Usage: nostr reply <note|nevent|hex id> -message <text>")
+		os.Exit(1)
+	}
+
+	client := newClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	report, err := client.Reply(ctx, parentRef, *message)
+	printReport(report, *jsonOutput)
+	if err != nil {
+		fmt.Printf("Error replying: %v\n", err)
+		os.Exit(1)
+	}
+
+	if !*jsonOutput {
+		fmt.Println("Reply sent successfully!")
+		fmt.Printf("Event ID: %s\n", report.NoteID())
+		fmt.Printf("View at: https://njump.me/%s\n", report.NoteID())
+	}
+}
+
+func handleThreadCommand(args []string) {
+	cmd := flag.NewFlagSet("thread", flag.ExitOnError)
+	keys := addKeyFlags(cmd, "", "", "")
+	relayURLs := cmd.String("relays", "wss://relay.damus.io", "Comma-separated list of relay URLs")
+	timeout := cmd.Duration("timeout", 10*time.Second, "Timeout for relay operations")
+
+	ref, args := leadingArg(args)
+	parseWithProfile(cmd, args, keys, false)
+	if ref == "" {
+		ref = cmd.Arg(0)
+	}
+	if ref == "" {
+		fmt.Println("Usage: nostr thread <note|nevent|hex id>")
+		os.Exit(1)
+	}
+
+	client := newReadClient(keys, parseRelayList(*relayURLs), *timeout)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	root, err := client.FetchThread(ctx, ref)
+	if err != nil {
+		fmt.Printf("Error fetching thread: %v\n", err)
+		os.Exit(1)
+	}
+
+	printThread(root, 0)
+}
+
+// printThread renders a reply tree, indenting each level of replies
+func printThread(node *nostr.ThreadNode, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	if node.Event == nil {
+		fmt.Printf("%s[%s] (root note not found)\n", indent, shortKey(node.ID))
+	} else {
+		fmt.Printf("%s[%s] %s %s:\n", indent, shortKey(node.ID),
+			node.Event.CreatedAt.Time().Format("2006-01-02 15:04"), shortKey(displayKey(node.Event.PubKey)))
+		for _, line := range strings.Split(node.Event.Content, "\n") {
+			fmt.Printf("%s  %s\n", indent, line)
+		}
+	}
+
+	for _, reply := range node.Replies {
+		printThread(reply, depth+1)
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	ErrProfileNotFound = errors.New("profile not found")
+)
+
+// Profile is a named identity with its key source and relay defaults
+type Profile struct {
+	// Key source, checked in this order: bunker, nsec (nsec or ncryptsec), key (hex), key_env
+	Bunker string `json:"bunker,omitempty"`
+	Nsec   string `json:"nsec,omitempty"`
+	Key    string `json:"key,omitempty"`
+	// KeyEnv names an environment variable holding the key, so it stays out of the file
+	KeyEnv string `json:"key_env,omitempty"`
+
+	Relays   []string `json:"relays,omitempty"`
+	DMRelays []string `json:"dm_relays,omitempty"`
+	ClientID string   `json:"client_id,omitempty"`
+
+	// UploadServer and UploadType ("blossom" or "nip96") are used for NIP-17 file messages
+	UploadServer string `json:"upload_server,omitempty"`
+	UploadType   string `json:"upload_type,omitempty"`
+}
+
+// Config is the contents of the CLI config file
+type Config struct {
+	DefaultProfile string              `json:"default_profile,omitempty"`
+	Profiles       map[string]*Profile `json:"profiles"`
+}
+
+// DefaultPath returns $NOSTR_CONFIG or the per-user config path, e.g. ~/.config/nostr/config.json
+func DefaultPath() (string, error) {
+	if path := os.Getenv("NOSTR_CONFIG"); path != "" {
+		return path, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "nostr", "config.json"), nil
+}
+
+// Load reads a config file. A missing file yields an empty config.
+func Load(path string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]*Profile{}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+
+	return cfg, nil
+}
+
+// Profile returns the named profile, or the default profile when name is empty.
+// It returns nil without error when no name is given and there is no default.
+func (c *Config) Profile(name string) (*Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, name)
+	}
+	return profile, nil
+}
+
+// PrivateKey returns the key held in the environment variable named by KeyEnv
+func (p *Profile) PrivateKey() string {
+	if p.KeyEnv == "" {
+		return ""
+	}
+	return os.Getenv(p.KeyEnv)
+}
+
+// RelaysFor returns the DM relays when dm is set and configured, otherwise the general relays
+func (p *Profile) RelaysFor(dm bool) []string {
+	if dm && len(p.DMRelays) > 0 {
+		return p.DMRelays
+	}
+	return p.Relays
+}
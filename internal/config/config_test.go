@@ -0,0 +1,111 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadRoundTrip(t *testing.T) {
+	want := &Config{
+		DefaultProfile: "work",
+		Profiles: map[string]*Profile{
+			"work": {
+				Bunker:       "bunker://abc?relay=wss://bunker.example",
+				Relays:       []string{"wss://a.example", "wss://b.example"},
+				DMRelays:     []string{"wss://dm.example"},
+				ClientID:     "nostr-cli",
+				UploadServer: "https://blossom.example",
+				UploadType:   "blossom",
+			},
+			"home": {KeyEnv: "HOME_KEY", Relays: []string{"wss://c.example"}},
+		},
+	}
+
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Profiles == nil || len(cfg.Profiles) != 0 {
+		t.Errorf("Profiles = %v, want an empty map", cfg.Profiles)
+	}
+}
+
+func TestDefaultPathFromEnv(t *testing.T) {
+	t.Setenv("NOSTR_CONFIG", "/tmp/other.json")
+
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("DefaultPath: %v", err)
+	}
+	if path != "/tmp/other.json" {
+		t.Errorf("DefaultPath = %q, want $NOSTR_CONFIG", path)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	work := &Profile{ClientID: "work"}
+	home := &Profile{ClientID: "home"}
+
+	tests := []struct {
+		name    string
+		cfg     *Config
+		profile string
+		want    *Profile
+		err     error
+	}{
+		{"named", &Config{DefaultProfile: "work", Profiles: map[string]*Profile{"work": work, "home": home}}, "home", home, nil},
+		{"default", &Config{DefaultProfile: "work", Profiles: map[string]*Profile{"work": work, "home": home}}, "", work, nil},
+		{"no default", &Config{Profiles: map[string]*Profile{"work": work}}, "", nil, nil},
+		{"unknown", &Config{Profiles: map[string]*Profile{"work": work}}, "play", nil, ErrProfileNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.cfg.Profile(tt.profile)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Profile(%q) error = %v, want %v", tt.profile, err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("Profile(%q) = %+v, want %+v", tt.profile, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelaysFor(t *testing.T) {
+	withDM := &Profile{Relays: []string{"wss://a.example"}, DMRelays: []string{"wss://dm.example"}}
+	withoutDM := &Profile{Relays: []string{"wss://a.example"}}
+
+	if got := withDM.RelaysFor(true); !reflect.DeepEqual(got, withDM.DMRelays) {
+		t.Errorf("RelaysFor(true) = %v, want the DM relays", got)
+	}
+	if got := withDM.RelaysFor(false); !reflect.DeepEqual(got, withDM.Relays) {
+		t.Errorf("RelaysFor(false) = %v, want the general relays", got)
+	}
+	if got := withoutDM.RelaysFor(true); !reflect.DeepEqual(got, withoutDM.Relays) {
+		t.Errorf("RelaysFor(true) without DM relays = %v, want the general relays", got)
+	}
+}
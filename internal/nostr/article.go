@@ -0,0 +1,189 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrMissingArticleID   = errors.New("article has no d identifier")
+	ErrInvalidFrontMatter = errors.New("invalid YAML front matter")
+	ErrInvalidPublishedAt = errors.New("invalid published_at, expected a unix timestamp, RFC 3339 time or YYYY-MM-DD date")
+	ErrUnterminatedHeader = errors.New("front matter is missing its closing ---")
+)
+
+// Article is a NIP-23 long-form post, written in Markdown
+type Article struct {
+	Identifier  string    `yaml:"d"`
+	Title       string    `yaml:"title"`
+	Summary     string    `yaml:"summary"`
+	Image       string    `yaml:"image"`
+	PublishedAt time.Time `yaml:"-"`
+	Hashtags    []string  `yaml:"tags"`
+	Content     string    `yaml:"-"`
+}
+
+// ParseArticle reads a Markdown document with optional YAML front matter between --- lines.
+// Recognised fields are title, summary, image, published_at, tags and d.
+func ParseArticle(data []byte) (*Article, error) {
+	article := &Article{}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	if !strings.HasPrefix(text, "---\n") {
+		article.Content = strings.TrimSpace(text)
+		return article, nil
+	}
+
+	header, body, found := splitFrontMatter(text[len("---\n"):])
+	if !found {
+		return nil, ErrUnterminatedHeader
+	}
+
+	// published_at is decoded separately as it may be a number or a date
+	var fields struct {
+		Article     `yaml:",inline"`
+		PublishedAt string `yaml:"published_at"`
+	}
+	if err := yaml.Unmarshal([]byte(header), &fields); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFrontMatter, err)
+	}
+
+	*article = fields.Article
+	article.Content = strings.TrimSpace(body)
+	if fields.PublishedAt != "" {
+		publishedAt, err := parsePublishedAt(fields.PublishedAt)
+		if err != nil {
+			return nil, err
+		}
+		article.PublishedAt = publishedAt
+	}
+
+	return article, nil
+}
+
+// splitFrontMatter splits the text after the opening --- at the closing marker, the first line
+// that is exactly --- (trailing spaces allowed). Lines such as ---- or ---foo belong to the header.
+func splitFrontMatter(text string) (header, body string, found bool) {
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if strings.TrimRight(line, " \t\n") == "---" {
+			return text[:offset], text[offset+len(line):], true
+		}
+		offset += len(line)
+	}
+	return "", "", false
+}
+
+// parsePublishedAt accepts a unix timestamp, an RFC 3339 time or a YYYY-MM-DD date
+func parsePublishedAt(value string) (time.Time, error) {
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrInvalidPublishedAt
+}
+
+// PublishArticle publishes an article as a kind 30023 addressable event, or a kind 30024 draft.
+// Publishing again with the same identifier replaces the article. Without a published_at, the
+// one of the previous version is kept, so only the first publication sets it.
+// It returns the publish report and the article's naddr.
+func (c *Client) PublishArticle(ctx context.Context, article *Article, draft bool, clientID string) (*PublishReport, string, error) {
+	if article.Identifier == "" {
+		return nil, "", ErrMissingArticleID
+	}
+
+	kind := nostr.KindArticle
+	if draft {
+		kind = nostr.KindDraftArticle
+	}
+
+	// Look up the version we are replacing
+	events, err := c.query(ctx, nostr.Filter{
+		Kinds:   []int{kind},
+		Authors: []string{c.pubKey},
+		Tags:    nostr.TagMap{"d": []string{article.Identifier}},
+	}, c.Relays())
+	if err != nil {
+		return nil, "", err
+	}
+	previous := newestEvent(events)
+
+	// Create the event
+	ev := nostr.Event{
+		PubKey:    c.pubKey,
+		CreatedAt: replacementTimestamp(previous),
+		Kind:      kind,
+		Tags:      nostr.Tags{{"d", article.Identifier}},
+		Content:   article.Content,
+	}
+
+	if article.Title != "" {
+		ev.Tags = append(ev.Tags, nostr.Tag{"title", article.Title})
+	}
+	if article.Summary != "" {
+		ev.Tags = append(ev.Tags, nostr.Tag{"summary", article.Summary})
+	}
+	if article.Image != "" {
+		ev.Tags = append(ev.Tags, nostr.Tag{"image", article.Image})
+	}
+
+	// Keep the original publication date across updates
+	publishedAt := strconv.FormatInt(int64(ev.CreatedAt), 10)
+	if !article.PublishedAt.IsZero() {
+		publishedAt = strconv.FormatInt(article.PublishedAt.Unix(), 10)
+	} else if previous != nil {
+		if tag := previous.Tags.Find("published_at"); len(tag) >= 2 {
+			publishedAt = tag[1]
+		}
+	}
+	ev.Tags = append(ev.Tags, nostr.Tag{"published_at", publishedAt})
+
+	hashtags := []string{}
+	for _, hashtag := range article.Hashtags {
+		hashtag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(hashtag), "#"))
+		if hashtag != "" {
+			hashtags = append(hashtags, hashtag)
+		}
+	}
+	for _, hashtag := range uniqueStrings(hashtags) {
+		ev.Tags = append(ev.Tags, nostr.Tag{"t", hashtag})
+	}
+
+	// Add client tag
+	ev.Tags = append(ev.Tags, nostr.Tag{"client", clientID})
+
+	// Sign the event
+	err = c.signer.SignEvent(ctx, &ev)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Publish the event to our relays and our NIP-65 write relays
+	report, err := c.publishRouted(ctx, &ev)
+	if err != nil {
+		return report, "", err
+	}
+
+	// Point readers at a couple of the relays it was published to
+	relayHints := c.Relays()
+	if len(relayHints) > 2 {
+		relayHints = relayHints[:2]
+	}
+	naddr, err := nip19.EncodeEntity(c.pubKey, kind, article.Identifier, relayHints)
+	if err != nil {
+		return report, "", err
+	}
+	return report, naddr, nil
+}
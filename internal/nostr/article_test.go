@@ -0,0 +1,134 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestParseArticle(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want Article
+	}{
+		{
+			name: "no front matter",
+			doc:  "# Hello\n\nBody\n",
+			want: Article{Content: "# Hello\n\nBody"},
+		},
+		{
+			name: "front matter",
+			doc:  "---\nd: hello\ntitle: Hello\nsummary: A greeting\nimage: https://example.com/a.png\ntags: [nostr, go]\n---\n\n# Hello\n",
+			want: Article{Identifier: "hello", Title: "Hello", Summary: "A greeting", Image: "https://example.com/a.png",
+				Hashtags: []string{"nostr", "go"}, Content: "# Hello"},
+		},
+		{
+			name: "windows line endings",
+			doc:  "---\r\ntitle: Hello\r\n---\r\nBody\r\n",
+			want: Article{Title: "Hello", Content: "Body"},
+		},
+		{
+			name: "empty header",
+			doc:  "---\n---\nBody",
+			want: Article{Content: "Body"},
+		},
+		{
+			name: "no body",
+			doc:  "---\ntitle: Hello\n---",
+			want: Article{Title: "Hello"},
+		},
+		{
+			name: "closing marker with trailing spaces",
+			doc:  "---\ntitle: Hello\n---  \nBody",
+			want: Article{Title: "Hello", Content: "Body"},
+		},
+		{
+			name: "longer dashes are not the closing marker",
+			doc:  "---\ntitle: Hello\nsummary: |\n  ----\n---\nBody",
+			want: Article{Title: "Hello", Summary: "----\n", Content: "Body"},
+		},
+		{
+			name: "horizontal rules in the body are kept",
+			doc:  "---\ntitle: Hello\n---\nAbove\n\n---\n\nBelow",
+			want: Article{Title: "Hello", Content: "Above\n\n---\n\nBelow"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseArticle([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("ParseArticle: %v", err)
+			}
+			if got.Identifier != tt.want.Identifier || got.Title != tt.want.Title || got.Summary != tt.want.Summary ||
+				got.Image != tt.want.Image || got.Content != tt.want.Content || !slices.Equal(got.Hashtags, tt.want.Hashtags) {
+				t.Errorf("ParseArticle = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArticleErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want error
+	}{
+		{"unterminated", "---\ntitle: Hello\nBody", ErrUnterminatedHeader},
+		{"four dashes only", "---\ntitle: Hello\n----\nBody", ErrUnterminatedHeader},
+		{"marker followed by text", "---\ntitle: Hello\n---foo\nBody", ErrUnterminatedHeader},
+		{"invalid YAML", "---\ntitle: [Hello\n---\nBody", ErrInvalidFrontMatter},
+		{"invalid published_at", "---\npublished_at: last tuesday\n---\nBody", ErrInvalidPublishedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseArticle([]byte(tt.doc)); !errors.Is(err, tt.want) {
+				t.Errorf("ParseArticle error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArticlePublishedAt(t *testing.T) {
+	want := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, value := range []string{"1714521600", "2024-05-01T00:00:00Z", "2024-05-01"} {
+		article, err := ParseArticle([]byte("---\npublished_at: " + value + "\n---\nBody"))
+		if err != nil {
+			t.Fatalf("published_at %s: %v", value, err)
+		}
+		if !article.PublishedAt.Equal(want) {
+			t.Errorf("published_at %s = %v, want %v", value, article.PublishedAt, want)
+		}
+	}
+}
+
+func TestPublishArticleKeepsPublishedAt(t *testing.T) {
+	relay := newTestRelay(t)
+	client := newTestClient(t, relay.URL)
+	ctx := context.Background()
+
+	article := &Article{Identifier: "hello", Title: "Hello", Content: "First", PublishedAt: time.Unix(1714521600, 0)}
+	if _, _, err := client.PublishArticle(ctx, article, false, "test"); err != nil {
+		t.Fatalf("PublishArticle: %v", err)
+	}
+
+	// An update without published_at replaces the article but keeps its date
+	update := &Article{Identifier: "hello", Title: "Hello again", Content: "Second"}
+	if _, _, err := client.PublishArticle(ctx, update, false, "test"); err != nil {
+		t.Fatalf("PublishArticle: %v", err)
+	}
+
+	stored := relay.Query(nostr.Filter{Kinds: []int{nostr.KindArticle}})
+	if len(stored) != 1 {
+		t.Fatalf("relay has %d versions, want the latest only", len(stored))
+	}
+	if stored[0].Content != "Second" || stored[0].Tags.FindWithValue("published_at", "1714521600") == nil {
+		t.Errorf("stored article = %+v, want the update with the original published_at", stored[0])
+	}
+}
@@ -0,0 +1,388 @@
+package nostr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip44"
+	"github.com/nbd-wtf/go-nostr/nip46"
+)
+
+var (
+	ErrInvalidBunkerURI   = errors.New("invalid bunker URI, expected bunker://<pubkey>?relay=...")
+	ErrBunkerUnreachable  = errors.New("could not reach the bunker through any of its relays")
+	ErrBunkerRefused      = errors.New("bunker refused the request")
+	ErrBunkerClosed       = errors.New("bunker signer is closed")
+	ErrInvalidSignedEvent = errors.New("bunker returned an event that doesn't match the one sent for signing")
+)
+
+// BunkerSigner is a Signer that delegates to a NIP-46 remote signer (bunker) over relays
+type BunkerSigner struct {
+	// secretKey identifies this client to the bunker, remote is the bunker's own key
+	secretKey       string
+	remote          string
+	conversationKey [32]byte
+	relays          []*relaySocket
+	onAuth          func(authURL string)
+	pubKey          string
+
+	// lifetime is cancelled by Close; wg tracks the response listeners and requests in flight
+	lifetime context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	closed  bool
+	serial  int
+	pending map[string]chan nip46.Response
+}
+
+// BunkerURI holds the parts of a bunker:// connection string
+type BunkerURI struct {
+	PublicKey string
+	Relays    []string
+	Secret    string
+}
+
+// ParseBunkerURI parses a bunker://<remote-signer-pubkey>?relay=wss://...&secret=... URI
+func ParseBunkerURI(uri string) (*BunkerURI, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "bunker" {
+		return nil, ErrInvalidBunkerURI
+	}
+
+	if !nostr.IsValidPublicKey(parsed.Host) {
+		return nil, ErrInvalidBunkerURI
+	}
+
+	relays := parsed.Query()["relay"]
+	if len(relays) == 0 {
+		return nil, ErrInvalidBunkerURI
+	}
+
+	return &BunkerURI{
+		PublicKey: parsed.Host,
+		Relays:    relays,
+		Secret:    parsed.Query().Get("secret"),
+	}, nil
+}
+
+// NewBunkerSigner connects to a remote signer described by a bunker:// URI.
+// clientSecretKey identifies this client to the bunker; a random one is used if empty.
+// onAuth, if not nil, receives any auth_url the bunker asks the user to visit.
+func NewBunkerSigner(ctx context.Context, bunkerURI, clientSecretKey string, onAuth func(authURL string)) (*BunkerSigner, error) {
+	uri, err := ParseBunkerURI(bunkerURI)
+	if err != nil {
+		return nil, err
+	}
+
+	if clientSecretKey == "" {
+		clientSecretKey = nostr.GeneratePrivateKey()
+	}
+	if onAuth == nil {
+		onAuth = func(string) {}
+	}
+
+	clientPubKey, err := nostr.GetPublicKey(clientSecretKey)
+	if err != nil {
+		return nil, ErrInvalidKeyFormat
+	}
+	conversationKey, err := nip44.GenerateConversationKey(uri.PublicKey, clientSecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// The bunker's relay subscriptions live until Close, not until ctx expires
+	lifetime, cancel := context.WithCancel(context.Background())
+	signer := &BunkerSigner{
+		secretKey:       clientSecretKey,
+		remote:          uri.PublicKey,
+		conversationKey: conversationKey,
+		onAuth:          onAuth,
+		lifetime:        lifetime,
+		cancel:          cancel,
+		pending:         make(map[string]chan nip46.Response),
+	}
+
+	if err := signer.listen(ctx, uri.Relays, clientPubKey); err != nil {
+		signer.Close()
+		return nil, err
+	}
+
+	if _, err := signer.rpc(ctx, "connect", uri.PublicKey, uri.Secret); err != nil {
+		signer.Close()
+		return nil, err
+	}
+
+	// The user's key is not necessarily the bunker's own key, so ask for it
+	signer.pubKey, err = signer.rpc(ctx, "get_public_key")
+	if err != nil {
+		signer.Close()
+		return nil, err
+	}
+	if !nostr.IsValidPublicKey(signer.pubKey) {
+		signer.Close()
+		return nil, fmt.Errorf("%w: invalid public key %q", ErrBunkerRefused, signer.pubKey)
+	}
+
+	return signer, nil
+}
+
+// listen connects to the bunker's relays and subscribes to the responses addressed to clientPubKey.
+// It fails only if none of the relays can be reached.
+func (s *BunkerSigner) listen(ctx context.Context, relayURLs []string, clientPubKey string) error {
+	since := nostr.Now()
+	filter := nostr.Filter{
+		Kinds:     []int{nostr.KindNostrConnect},
+		Authors:   []string{s.remote},
+		Tags:      nostr.TagMap{"p": []string{clientPubKey}},
+		Since:     &since,
+		LimitZero: true,
+	}
+
+	relays := make([]*relaySocket, len(relayURLs))
+	subs := make([]*socketSub, len(relayURLs))
+	errs := make([]error, len(relayURLs))
+
+	var wg sync.WaitGroup
+	for i, relayURL := range relayURLs {
+		wg.Add(1)
+		go func(i int, relayURL string) {
+			defer wg.Done()
+			relays[i], errs[i] = dialRelay(ctx, nostr.NormalizeURL(relayURL))
+			if errs[i] != nil {
+				return
+			}
+			if subs[i], errs[i] = relays[i].subscribe(filter); errs[i] != nil {
+				relays[i].Close()
+			}
+		}(i, relayURL)
+	}
+	wg.Wait()
+
+	var lastErr error
+	for i := range relayURLs {
+		if errs[i] != nil {
+			lastErr = errs[i]
+			continue
+		}
+		s.relays = append(s.relays, relays[i])
+		s.wg.Add(1)
+		go s.receive(relays[i], subs[i])
+	}
+
+	if len(s.relays) == 0 {
+		return fmt.Errorf("%w: %v", ErrBunkerUnreachable, lastErr)
+	}
+	return nil
+}
+
+// receive hands the responses arriving on one relay to the requests waiting for them until Close
+func (s *BunkerSigner) receive(relay *relaySocket, sub *socketSub) {
+	defer s.wg.Done()
+
+	for {
+		select {
+		case event := <-sub.events:
+			s.dispatch(event)
+		case <-sub.ended:
+			return
+		case <-relay.Done():
+			return
+		case <-s.lifetime.Done():
+			return
+		}
+	}
+}
+
+// dispatch decrypts a response and passes it to the request with the same ID.
+// Responses that arrive through more than one relay are delivered once.
+func (s *BunkerSigner) dispatch(event *nostr.Event) {
+	plaintext, err := nip44.Decrypt(event.Content, s.conversationKey)
+	if err != nil {
+		return
+	}
+
+	var response nip46.Response
+	if err := json.Unmarshal([]byte(plaintext), &response); err != nil {
+		return
+	}
+
+	// The bunker wants the user to approve the request in a browser, the real answer follows
+	if response.Result == "auth_url" {
+		s.onAuth(response.Error)
+		return
+	}
+
+	s.mu.Lock()
+	waiting, ok := s.pending[response.ID]
+	s.mu.Unlock()
+
+	if ok {
+		select {
+		case waiting <- response:
+		default:
+		}
+	}
+}
+
+// rpc sends a request to the bunker through every relay and waits for its response
+func (s *BunkerSigner) rpc(ctx context.Context, method string, params ...string) (string, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return "", ErrBunkerClosed
+	}
+	s.serial++
+	id := strconv.Itoa(s.serial)
+	waiting := make(chan nip46.Response, 1)
+	s.pending[id] = waiting
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.pending, id)
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	// Give up when the caller does or the signer is closed
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(s.lifetime, cancel)
+	defer stop()
+
+	request, err := json.Marshal(nip46.Request{ID: id, Method: method, Params: params})
+	if err != nil {
+		return "", err
+	}
+	content, err := nip44.Encrypt(string(request), s.conversationKey)
+	if err != nil {
+		return "", err
+	}
+
+	event := nostr.Event{
+		Kind:      nostr.KindNostrConnect,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{{"p", s.remote}},
+		Content:   content,
+	}
+	if err := event.Sign(s.secretKey); err != nil {
+		return "", err
+	}
+
+	// One relay accepting the request is enough for the bunker to see it
+	errs := make([]error, len(s.relays))
+	var wg sync.WaitGroup
+	for i, relay := range s.relays {
+		wg.Add(1)
+		go func(i int, relay *relaySocket) {
+			defer wg.Done()
+			errs[i] = relay.Publish(ctx, event)
+		}(i, relay)
+	}
+	wg.Wait()
+
+	if !slices.Contains(errs, nil) {
+		return "", fmt.Errorf("%w: %v", ErrBunkerUnreachable, errors.Join(errs...))
+	}
+
+	select {
+	case response := <-waiting:
+		if response.Error != "" {
+			return "", fmt.Errorf("%w: %s", ErrBunkerRefused, response.Error)
+		}
+		return response.Result, nil
+	case <-ctx.Done():
+		if s.lifetime.Err() != nil {
+			return "", ErrBunkerClosed
+		}
+		return "", ctx.Err()
+	}
+}
+
+// GetPublicKey returns the public key the bunker signs for
+func (s *BunkerSigner) GetPublicKey(ctx context.Context) (string, error) {
+	return s.pubKey, nil
+}
+
+// SignEvent asks the bunker to sign the event. The signed event is only accepted if it is
+// the event that was sent, by the bunker's user and with a valid signature.
+func (s *BunkerSigner) SignEvent(ctx context.Context, event *nostr.Event) error {
+	event.PubKey = s.pubKey
+	request, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.rpc(ctx, "sign_event", string(request))
+	if err != nil {
+		return err
+	}
+
+	var signed nostr.Event
+	if err := json.Unmarshal([]byte(result), &signed); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignedEvent, err)
+	}
+	if signed.PubKey != s.pubKey || signed.Kind != event.Kind || signed.Content != event.Content ||
+		!slices.EqualFunc(signed.Tags, event.Tags, slices.Equal) {
+		return ErrInvalidSignedEvent
+	}
+	if !signed.CheckID() {
+		return fmt.Errorf("%w: event id does not match", ErrInvalidSignedEvent)
+	}
+	if ok, _ := signed.CheckSignature(); !ok {
+		return fmt.Errorf("%w: signature verification failed", ErrInvalidSignedEvent)
+	}
+
+	*event = signed
+	return nil
+}
+
+// NIP04Encrypt asks the bunker to encrypt plaintext for a recipient using NIP-04
+func (s *BunkerSigner) NIP04Encrypt(ctx context.Context, plaintext, recipientPubKey string) (string, error) {
+	return s.rpc(ctx, "nip04_encrypt", recipientPubKey, plaintext)
+}
+
+// NIP04Decrypt asks the bunker to decrypt a NIP-04 payload
+func (s *BunkerSigner) NIP04Decrypt(ctx context.Context, ciphertext, counterpartyPubKey string) (string, error) {
+	return s.rpc(ctx, "nip04_decrypt", counterpartyPubKey, ciphertext)
+}
+
+// NIP44Encrypt asks the bunker to encrypt plaintext for a recipient using NIP-44
+func (s *BunkerSigner) NIP44Encrypt(ctx context.Context, plaintext, recipientPubKey string) (string, error) {
+	return s.rpc(ctx, "nip44_encrypt", recipientPubKey, plaintext)
+}
+
+// NIP44Decrypt asks the bunker to decrypt a NIP-44 payload
+func (s *BunkerSigner) NIP44Decrypt(ctx context.Context, ciphertext, counterpartyPubKey string) (string, error) {
+	return s.rpc(ctx, "nip44_decrypt", counterpartyPubKey, ciphertext)
+}
+
+// Close stops listening for bunker responses and disconnects from the bunker's relays.
+// Requests still waiting for an answer fail with ErrBunkerClosed.
+func (s *BunkerSigner) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	// Wait for the listeners and requests to return before closing the relays under them
+	s.cancel()
+	s.wg.Wait()
+
+	for _, relay := range s.relays {
+		relay.Close()
+	}
+}
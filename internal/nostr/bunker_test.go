@@ -0,0 +1,220 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/relaytest"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip44"
+)
+
+// newTestBunker starts a NIP-46 signer on relay and connects a BunkerSigner to it
+func newTestBunker(t *testing.T, relay *relaytest.Relay) (*relaytest.Bunker, *BunkerSigner) {
+	t.Helper()
+
+	bunker, err := relaytest.NewBunker(relay)
+	if err != nil {
+		t.Fatalf("NewBunker: %v", err)
+	}
+	t.Cleanup(bunker.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	signer, err := NewBunkerSigner(ctx, bunker.URI(), "", nil)
+	if err != nil {
+		t.Fatalf("NewBunkerSigner: %v", err)
+	}
+	t.Cleanup(signer.Close)
+
+	return bunker, signer
+}
+
+func TestBunkerSignerConnect(t *testing.T) {
+	relay := newTestRelay(t)
+	bunker, signer := newTestBunker(t, relay)
+
+	pubKey, err := signer.GetPublicKey(context.Background())
+	if err != nil {
+		t.Fatalf("GetPublicKey: %v", err)
+	}
+	if pubKey != bunker.PublicKey {
+		t.Errorf("public key = %s, want the bunker's %s", pubKey, bunker.PublicKey)
+	}
+
+	methods := bunker.Methods()
+	if !slices.Equal(methods, []string{"connect", "get_public_key"}) {
+		t.Errorf("bunker answered %v, want connect then get_public_key", methods)
+	}
+}
+
+func TestBunkerSignerSignEvent(t *testing.T) {
+	relay := newTestRelay(t)
+	bunker, signer := newTestBunker(t, relay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	event := &nostr.Event{Kind: nostr.KindTextNote, Content: "signed remotely", CreatedAt: nostr.Now(), Tags: nostr.Tags{}}
+	if err := signer.SignEvent(ctx, event); err != nil {
+		t.Fatalf("SignEvent: %v", err)
+	}
+
+	if event.PubKey != bunker.PublicKey {
+		t.Errorf("event pubkey = %s, want %s", event.PubKey, bunker.PublicKey)
+	}
+	if ok, err := event.CheckSignature(); !ok {
+		t.Errorf("signature does not verify: %v", err)
+	}
+
+	// The signed event is accepted by a client that publishes through the bunker
+	client := newSignerClient(t, signer, relay.URL)
+	if _, err := client.SendPublicPost(ctx, "published through the bunker", "", nil, 0); err != nil {
+		t.Fatalf("SendPublicPost: %v", err)
+	}
+	if got := len(relay.Query(nostr.Filter{Kinds: []int{nostr.KindTextNote}, Authors: []string{bunker.PublicKey}})); got != 1 {
+		t.Errorf("relay has %d notes by the bunker key, want 1", got)
+	}
+}
+
+func TestBunkerSignerRejectsTamperedEvents(t *testing.T) {
+	relay := newTestRelay(t)
+	bunker, signer := newTestBunker(t, relay)
+
+	tests := []struct {
+		name   string
+		tamper func(event *nostr.Event)
+	}{
+		{"other content", func(event *nostr.Event) {
+			event.Content = "something else"
+			event.Sign(bunker.SecretKey)
+		}},
+		{"other tags", func(event *nostr.Event) {
+			event.Tags = append(event.Tags, nostr.Tag{"t", "injected"})
+			event.Sign(bunker.SecretKey)
+		}},
+		{"other kind", func(event *nostr.Event) {
+			event.Kind = nostr.KindReaction
+			event.Sign(bunker.SecretKey)
+		}},
+		{"other key", func(event *nostr.Event) {
+			event.Sign(nostr.GeneratePrivateKey())
+		}},
+		{"bad signature", func(event *nostr.Event) {
+			event.Sig = strings.Repeat("0", 128)
+		}},
+		{"bad id", func(event *nostr.Event) {
+			event.ID = strings.Repeat("0", 64)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bunker.Tamper(tt.tamper)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			event := &nostr.Event{Kind: nostr.KindTextNote, Content: "signed remotely", CreatedAt: nostr.Now(), Tags: nostr.Tags{}}
+			if err := signer.SignEvent(ctx, event); !errors.Is(err, ErrInvalidSignedEvent) {
+				t.Errorf("SignEvent error = %v, want ErrInvalidSignedEvent", err)
+			}
+			if event.Sig != "" {
+				t.Error("SignEvent kept the bunker's signature")
+			}
+		})
+	}
+}
+
+func TestBunkerSignerCloseFailsPendingRequests(t *testing.T) {
+	relay := newTestRelay(t)
+	bunker, signer := newTestBunker(t, relay)
+
+	// With the bunker gone the request waits for an answer that never comes
+	bunker.Close()
+	done := make(chan error, 1)
+	go func() {
+		_, err := signer.NIP44Encrypt(context.Background(), "never sent", bunker.PublicKey)
+		done <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	signer.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrBunkerClosed) {
+			t.Errorf("NIP44Encrypt error = %v, want ErrBunkerClosed", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close left a request waiting")
+	}
+
+	if _, err := signer.NIP44Encrypt(context.Background(), "after close", bunker.PublicKey); !errors.Is(err, ErrBunkerClosed) {
+		t.Errorf("NIP44Encrypt after Close error = %v, want ErrBunkerClosed", err)
+	}
+}
+
+func TestBunkerSignerNIP44(t *testing.T) {
+	relay := newTestRelay(t)
+	bunker, signer := newTestBunker(t, relay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	peerKey := nostr.GeneratePrivateKey()
+	peerPubKey, _ := nostr.GetPublicKey(peerKey)
+	conversationKey, err := nip44.GenerateConversationKey(bunker.PublicKey, peerKey)
+	if err != nil {
+		t.Fatalf("GenerateConversationKey: %v", err)
+	}
+
+	// Encrypt remotely, decrypt locally as the peer
+	ciphertext, err := signer.NIP44Encrypt(ctx, "to the peer", peerPubKey)
+	if err != nil {
+		t.Fatalf("NIP44Encrypt: %v", err)
+	}
+	plaintext, err := nip44.Decrypt(ciphertext, conversationKey)
+	if err != nil {
+		t.Fatalf("peer could not decrypt: %v", err)
+	}
+	if plaintext != "to the peer" {
+		t.Errorf("peer decrypted %q", plaintext)
+	}
+
+	// Encrypt locally as the peer, decrypt remotely
+	ciphertext, err = nip44.Encrypt("from the peer", conversationKey)
+	if err != nil {
+		t.Fatalf("nip44.Encrypt: %v", err)
+	}
+	plaintext, err = signer.NIP44Decrypt(ctx, ciphertext, peerPubKey)
+	if err != nil {
+		t.Fatalf("NIP44Decrypt: %v", err)
+	}
+	if plaintext != "from the peer" {
+		t.Errorf("bunker decrypted %q", plaintext)
+	}
+
+	methods := bunker.Methods()
+	if !slices.Contains(methods, "nip44_encrypt") || !slices.Contains(methods, "nip44_decrypt") {
+		t.Errorf("bunker answered %v, want nip44_encrypt and nip44_decrypt", methods)
+	}
+}
+
+func TestClientCloseRunsOnClose(t *testing.T) {
+	relay := newTestRelay(t)
+	client := newTestClient(t, relay.URL)
+
+	calls := 0
+	client.OnClose(func() { calls++ })
+	client.Close()
+	client.Close()
+
+	if calls != 1 {
+		t.Errorf("OnClose callback ran %d times, want 1", calls)
+	}
+}
@@ -2,23 +2,70 @@ package nostr
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip19"
 )
 
-// Client represents a simplified Nostr client 
+// Client represents a simplified Nostr client backed by a pool of relay connections
 type Client struct {
-	sk      string
-	pubKey  string
-	npub    string
-	relay   *nostr.Relay
-	timeout time.Duration
+	signer Signer
+	pubKey string
+	npub   string
+	mu     sync.Mutex
+	relays map[string]*relayConn
+	// transient holds connections to relays outside the pool, such as relay hints and other users'
+	// inbox relays. They are reused while the client lives but never become publish or query targets.
+	transient map[string]*relayConn
+	timeout   time.Duration
+	policy    ReconnectPolicy
+	onState   func(StateChange)
+	onClose   []func()
+
+	// dmRelays caches recipients' NIP-17 relay lists between sends,
+	// readRelays and writeRelays the two halves of users' NIP-65 relay lists
+	dmRelays    *relayListCache
+	readRelays  *relayListCache
+	writeRelays *relayListCache
+	outbox      bool
+
+	// relayInfo caches relays' NIP-11 documents, fetched to check NIP-40 support
+	relayInfo *relayInfoCache
+}
+
+// relayConn is a relay connection that is opened on first use. Pooled connections
+// are supervised so they reconnect when the relay drops them; transient ones are simply redialed.
+type relayConn struct {
+	mu        sync.Mutex
+	url       string
+	relay     *relaySocket
+	state     ConnState
+	stop      chan struct{}
+	transient bool
+	// dialing is closed when the dial in progress, if any, finishes, and cancelDial aborts it.
+	// closes counts calls to close, so a dial that outlives one knows not to install its result.
+	dialing    chan struct{}
+	cancelDial context.CancelFunc
+	closes     int
+	// removed is set once the connection is dropped from the client and may never be dialed again
+	removed bool
 }
 
-func NewClient(privateKeyHex string, timeout time.Duration) (*Client, error) {
-	pubKey, err := nostr.GetPublicKey(privateKeyHex)
+// NewClient creates a client that signs and encrypts through the given Signer
+func NewClient(signer Signer, timeout time.Duration) (*Client, error) {
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	// Remote signers may need a round trip to report their key
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	pubKey, err := signer.GetPublicKey(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -29,17 +76,43 @@ func NewClient(privateKeyHex string, timeout time.Duration) (*Client, error) {
 		return nil, err
 	}
 
+	return &Client{
+		signer:    signer,
+		pubKey:    pubKey,
+		npub:      npub,
+		relays:    make(map[string]*relayConn),
+		transient: make(map[string]*relayConn),
+		timeout:   timeout,
+		policy:    DefaultReconnectPolicy(),
+
+		dmRelays:    newRelayListCache(DefaultRelayListTTL),
+		readRelays:  newRelayListCache(DefaultRelayListTTL),
+		writeRelays: newRelayListCache(DefaultRelayListTTL),
+		outbox:      true,
+		relayInfo:   newRelayInfoCache(),
+	}, nil
+}
+
+// NewReadOnlyClient creates a client without a key, for fetching public data such as profiles
+// and threads. Anything that has to sign or decrypt fails with ErrNoSigner.
+func NewReadOnlyClient(timeout time.Duration) *Client {
 	if timeout == 0 {
 		timeout = 10 * time.Second
 	}
 
 	return &Client{
-		sk:      privateKeyHex,
-		pubKey:  pubKey,
-		npub:    npub,
-		relay:   nil,
-		timeout: timeout,
-	}, nil
+		signer:    readOnlySigner{},
+		relays:    make(map[string]*relayConn),
+		transient: make(map[string]*relayConn),
+		timeout:   timeout,
+		policy:    DefaultReconnectPolicy(),
+
+		dmRelays:    newRelayListCache(DefaultRelayListTTL),
+		readRelays:  newRelayListCache(DefaultRelayListTTL),
+		writeRelays: newRelayListCache(DefaultRelayListTTL),
+		outbox:      true,
+		relayInfo:   newRelayInfoCache(),
+	}
 }
 
 // NewClientFromNsec creates a new Nostr client from an nsec private key
@@ -51,7 +124,12 @@ func NewClientFromNsec(nsecKey string, timeout time.Duration) (*Client, error) {
 	if prefix != "nsec" {
 		return nil, ErrInvalidKeyFormat
 	}
-	return NewClient(decoded.(string), timeout)
+
+	signer, err := NewKeySigner(decoded.(string))
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(signer, timeout)
 }
 
 // GetPublicKey returns the client's public key in hex format
@@ -64,22 +142,214 @@ func (c *Client) GetPublicKeyBech32() string {
 	return c.npub
 }
 
+// Signer returns the signer the client uses for events and encryption
+func (c *Client) Signer() Signer {
+	return c.signer
+}
+
+// AddRelay adds a relay to the pool and returns its normalized URL.
+// The connection is opened lazily the first time the relay is used.
+func (c *Client) AddRelay(url string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.addRelayLocked(url).url
+}
+
+func (c *Client) addRelayLocked(url string) *relayConn {
+	url = nostr.NormalizeURL(url)
+	conn, ok := c.relays[url]
+	if ok {
+		return conn
+	}
+
+	// Adopt an open transient connection rather than dialing again
+	if conn, ok = c.transient[url]; ok {
+		delete(c.transient, url)
+		conn.mu.Lock()
+		conn.transient = false
+		if conn.relay != nil && conn.relay.IsConnected() {
+			c.install(conn, conn.relay)
+		}
+		conn.mu.Unlock()
+	} else {
+		conn = &relayConn{url: url}
+	}
+	c.relays[url] = conn
+	return conn
+}
+
+// connLocked returns the pooled connection to url, or a transient one if url isn't in the pool
+func (c *Client) connLocked(url string) *relayConn {
+	url = nostr.NormalizeURL(url)
+	if conn, ok := c.relays[url]; ok {
+		return conn
+	}
+
+	conn, ok := c.transient[url]
+	if !ok {
+		conn = &relayConn{url: url, transient: true}
+		c.transient[url] = conn
+	}
+	return conn
+}
+
+// RemoveRelay closes the connection to a relay, if open, and removes it from the pool
+func (c *Client) RemoveRelay(url string) {
+	url = nostr.NormalizeURL(url)
+
+	c.mu.Lock()
+	conn, ok := c.relays[url]
+	delete(c.relays, url)
+	c.mu.Unlock()
+
+	if ok {
+		conn.remove()
+	}
+}
+
+// Relays returns the normalized URLs of every relay in the pool
+func (c *Client) Relays() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	urls := make([]string, 0, len(c.relays))
+	for url := range c.relays {
+		urls = append(urls, url)
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// ConnectToRelay adds a relay to the pool and connects to it right away
 func (c *Client) ConnectToRelay(ctx context.Context, url string) error {
-	// Close existing connection if any
-	if c.relay != nil {
-		c.relay.Close()
+	c.mu.Lock()
+	conn := c.addRelayLocked(url)
+	c.mu.Unlock()
+
+	_, err := c.connect(ctx, conn)
+	return err
+}
+
+// relay returns a live connection to url, connecting if needed. Relays outside the pool
+// get a transient connection and are not added to it.
+func (c *Client) relay(ctx context.Context, url string) (*relaySocket, error) {
+	c.mu.Lock()
+	conn := c.connLocked(url)
+	c.mu.Unlock()
+
+	return c.connect(ctx, conn)
+}
+
+// connect returns the open connection, dialing the relay if it isn't connected yet.
+// Only one dial per relay runs at a time and it runs without rc.mu held, so State and
+// callers of other relays never wait on it. A successful dial also cuts short any backoff
+// the supervisor is waiting out. Closing the connection aborts the dial.
+func (c *Client) connect(ctx context.Context, rc *relayConn) (*relaySocket, error) {
+	for {
+		rc.mu.Lock()
+		if rc.removed {
+			rc.mu.Unlock()
+			return nil, &RelayError{RelayURL: rc.url, Err: ErrConnectionClosed}
+		}
+		if rc.relay != nil && rc.relay.IsConnected() {
+			relay := rc.relay
+			rc.mu.Unlock()
+			return relay, nil
+		}
+		if rc.dialing == nil {
+			break
+		}
+
+		// Someone else is dialing, wait for their result
+		dialing := rc.dialing
+		rc.mu.Unlock()
+		select {
+		case <-dialing:
+		case <-ctx.Done():
+			return nil, &RelayError{RelayURL: rc.url, Err: ctx.Err()}
+		}
 	}
 
-	relay, err := nostr.RelayConnect(ctx, url)
+	connectCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	dialing := make(chan struct{})
+	rc.dialing = dialing
+	rc.cancelDial = cancel
+	closes := rc.closes
+	rc.mu.Unlock()
+
+	defer func() {
+		rc.mu.Lock()
+		rc.dialing = nil
+		rc.cancelDial = nil
+		rc.mu.Unlock()
+		close(dialing)
+	}()
+
+	relay, err := dialRelay(connectCtx, rc.url)
+
+	rc.mu.Lock()
+	if rc.closes != closes {
+		// Closed or removed from the pool while we were dialing
+		rc.mu.Unlock()
+		if relay != nil {
+			relay.Close()
+		}
+		return nil, &RelayError{RelayURL: rc.url, Err: ErrConnectionClosed}
+	}
 	if err != nil {
-		return err
+		recovering := rc.state == Reconnecting
+		rc.mu.Unlock()
+
+		// While the supervisor is still retrying, leave the state to it
+		if !recovering {
+			c.notify(rc, StateChange{Relay: rc.url, State: Failed, Err: err})
+		}
+		return nil, &RelayError{RelayURL: rc.url, Err: err}
+	}
+	if rc.relay != nil && rc.relay.IsConnected() {
+		// The supervisor reconnected while we were dialing, keep its connection
+		existing := rc.relay
+		rc.mu.Unlock()
+		relay.Close()
+		return existing, nil
+	}
+	c.install(rc, relay)
+	rc.mu.Unlock()
+
+	c.notify(rc, StateChange{Relay: rc.url, State: Connected})
+	return relay, nil
+}
+
+// close closes the connection, stops its supervisor and aborts any dial in progress.
+// The relayConn itself stays usable: the next connect dials again.
+func (rc *relayConn) close() {
+	rc.mu.Lock()
+	rc.closes++
+	rc.stopSupervisor()
+	if rc.cancelDial != nil {
+		rc.cancelDial()
+	}
+	relay := rc.relay
+	rc.relay = nil
+	rc.state = Disconnected
+	rc.mu.Unlock()
+
+	if relay != nil {
+		relay.Close()
 	}
+}
 
-	c.relay = relay
-	return nil
+// remove closes the connection for good, for relays dropped from the client
+func (rc *relayConn) remove() {
+	rc.mu.Lock()
+	rc.removed = true
+	rc.mu.Unlock()
+	rc.close()
 }
 
-func (c *Client) CreateTextNote(content string, tags [][]string) (*nostr.Event, error) {
+func (c *Client) CreateTextNote(ctx context.Context, content string, tags [][]string) (*nostr.Event, error) {
 	ev := nostr.Event{
 		PubKey:    c.pubKey,
 		CreatedAt: nostr.Timestamp(time.Now().Unix()),
@@ -94,7 +364,7 @@ func (c *Client) CreateTextNote(content string, tags [][]string) (*nostr.Event,
 		}
 	}
 
-	err := ev.Sign(c.sk)
+	err := c.signer.SignEvent(ctx, &ev)
 	if err != nil {
 		return nil, err
 	}
@@ -102,17 +372,165 @@ func (c *Client) CreateTextNote(content string, tags [][]string) (*nostr.Event,
 	return &ev, nil
 }
 
-func (c *Client) PublishEvent(ctx context.Context, event *nostr.Event) error {
-	if c.relay == nil {
-		return ErrNoRelayConnected
+// PublishEvent publishes an event to every relay in the pool and reports each relay's answer
+func (c *Client) PublishEvent(ctx context.Context, event *nostr.Event) (*PublishReport, error) {
+	relays := c.Relays()
+	if len(relays) == 0 {
+		return nil, ErrNoRelayConnected
+	}
+
+	report := (&PublishReport{}).add(c.publish(ctx, *event, relays))
+	return report, report.err()
+}
+
+// publish sends an event to the given relays concurrently and records every relay's outcome
+func (c *Client) publish(ctx context.Context, event nostr.Event, relayURLs []string) *EventReport {
+	report := newEventReport(event.ID, event.Kind)
+	report.Results = make([]RelayResult, len(relayURLs))
+
+	var wg sync.WaitGroup
+	for i, relayURL := range relayURLs {
+		wg.Add(1)
+		go func(i int, relayURL string) {
+			defer wg.Done()
+			report.Results[i] = c.publishTo(ctx, event, relayURL)
+		}(i, relayURL)
 	}
-	
-	return c.relay.Publish(ctx, *event)
+	wg.Wait()
+
+	return report
+}
+
+// publishTo sends an event to a single relay and times the round trip to its OK
+func (c *Client) publishTo(ctx context.Context, event nostr.Event, relayURL string) RelayResult {
+	result := RelayResult{Relay: nostr.NormalizeURL(relayURL)}
+
+	relay, err := c.relay(ctx, relayURL)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	start := time.Now()
+	err = relay.Publish(ctx, event)
+	if err != nil && !relay.IsConnected() {
+		// The connection dropped under us, redial once and retry
+		if relay, err = c.relay(ctx, relayURL); err == nil {
+			err = relay.Publish(ctx, event)
+		}
+	}
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Message = err.Error()
+		return result
+	}
+
+	result.Accepted = true
+	return result
+}
+
+// query runs a filter against the given relays concurrently until EOSE and returns the unique events found.
+// It only fails if no relay could be read.
+func (c *Client) query(ctx context.Context, filter nostr.Filter, relayURLs []string) ([]*nostr.Event, error) {
+	return c.queryRelays(ctx, filter, relayURLs, false)
+}
+
+// queryStrict is query for reads whose result will be written back: it fails if any relay
+// can't be read, since that relay may hold the only copy of the newest version
+func (c *Client) queryStrict(ctx context.Context, filter nostr.Filter, relayURLs []string) ([]*nostr.Event, error) {
+	return c.queryRelays(ctx, filter, relayURLs, true)
 }
 
+func (c *Client) queryRelays(ctx context.Context, filter nostr.Filter, relayURLs []string, strict bool) ([]*nostr.Event, error) {
+	if len(relayURLs) == 0 {
+		return nil, ErrNoRelayConnected
+	}
+
+	results := make([][]*nostr.Event, len(relayURLs))
+	errs := make([]error, len(relayURLs))
+
+	// Query every relay at once, each returns as soon as it sends EOSE
+	var wg sync.WaitGroup
+	for i, relayURL := range relayURLs {
+		wg.Add(1)
+		go func(i int, relayURL string) {
+			defer wg.Done()
+			results[i], errs[i] = c.queryOne(ctx, filter, relayURL)
+		}(i, relayURL)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	events := []*nostr.Event{}
+	var queryErr error
+	queried := false
+
+	for i := range relayURLs {
+		if errs[i] != nil {
+			if strict {
+				return nil, fmt.Errorf("%w: %v", ErrIncompleteRead, errs[i])
+			}
+			queryErr = errs[i]
+			continue
+		}
+		queried = true
+
+		for _, ev := range results[i] {
+			if seen[ev.ID] {
+				continue
+			}
+			seen[ev.ID] = true
+			events = append(events, ev)
+		}
+	}
+
+	if !queried {
+		return nil, queryErr
+	}
+
+	return events, nil
+}
+
+// queryOne fetches stored events matching filter from a single relay
+func (c *Client) queryOne(ctx context.Context, filter nostr.Filter, relayURL string) ([]*nostr.Event, error) {
+	relay, err := c.relay(ctx, relayURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// QuerySync returns once the relay sends EOSE
+	results, err := relay.QuerySync(ctx, filter)
+	if err != nil {
+		return nil, &RelayError{RelayURL: relay.url, Err: err}
+	}
+
+	return results, nil
+}
+
+// Close closes every relay connection and runs the OnClose callbacks.
+// Relays stay in the pool and reconnect on next use.
 func (c *Client) Close() {
-	if c.relay != nil {
-		c.relay.Close()
-		c.relay = nil
+	c.mu.Lock()
+	for _, conn := range c.relays {
+		conn.close()
+	}
+	for url, conn := range c.transient {
+		conn.remove()
+		delete(c.transient, url)
+	}
+	hooks := c.onClose
+	c.onClose = nil
+	c.mu.Unlock()
+
+	for _, fn := range hooks {
+		fn()
 	}
-} 
\ No newline at end of file
+}
+
+// OnClose registers a callback run once by the next Close, for resources that live
+// as long as the client, such as a BunkerSigner's relay subscriptions
+func (c *Client) OnClose(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onClose = append(c.onClose, fn)
+}
@@ -0,0 +1,109 @@
+package nostr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/relaytest"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+func TestOtherRelaysDoNotJoinPool(t *testing.T) {
+	tests := []struct {
+		name string
+		// reach makes client use other without adding it to the pool
+		reach func(t *testing.T, ctx context.Context, client *Client, pool, other *relaytest.Relay)
+	}{
+		{
+			name: "relay hint",
+			reach: func(t *testing.T, ctx context.Context, client *Client, pool, other *relaytest.Relay) {
+				// The note only exists on the hinted relay
+				note := postNote(t, newTestClient(t, other.URL), "hello")
+				nevent, _ := nip19.EncodeEvent(note.ID, []string{other.URL}, "")
+				if _, err := client.React(ctx, nevent, "+"); err != nil {
+					t.Fatalf("React: %v", err)
+				}
+			},
+		},
+		{
+			name: "NIP-17 inbox relay",
+			reach: func(t *testing.T, ctx context.Context, client *Client, pool, other *relaytest.Relay) {
+				bob := newTestClient(t)
+				if _, err := bob.PublishNIP17Preferences(ctx, []string{other.URL}); err != nil {
+					t.Fatalf("PublishNIP17Preferences: %v", err)
+				}
+				pool.AddEvent(other.Query(nostr.Filter{Kinds: []int{10050}})[0])
+
+				if _, err := client.SendNIP17DirectMessage(ctx, []string{bob.GetPublicKey()}, "hi", "", "", "test", 0); err != nil {
+					t.Fatalf("SendNIP17DirectMessage: %v", err)
+				}
+			},
+		},
+		{
+			name: "NIP-65 read relay",
+			reach: func(t *testing.T, ctx context.Context, client *Client, pool, other *relaytest.Relay) {
+				bob := newTestClient(t, pool.URL)
+				if _, err := bob.PublishRelayList(ctx, []RelayListEntry{{URL: other.URL, Read: true}}); err != nil {
+					t.Fatalf("PublishRelayList: %v", err)
+				}
+
+				if _, err := client.SendPublicPost(ctx, "hi bob", "test", nostr.Tags{{"p", bob.GetPublicKey()}}, 0); err != nil {
+					t.Fatalf("SendPublicPost: %v", err)
+				}
+				if n := kindCount(other, nostr.KindTextNote); n != 1 {
+					t.Fatalf("Bob's read relay has %d notes, want the mention", n)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, other := newTestRelay(t), newTestRelay(t)
+			ctx := context.Background()
+			client := newTestClient(t, pool.URL)
+
+			tt.reach(t, ctx, client, pool, other)
+
+			if relays := client.Relays(); len(relays) != 1 || relays[0] != nostr.NormalizeURL(pool.URL) {
+				t.Fatalf("Relays() = %v, want only the pool relay", relays)
+			}
+
+			// Unrelated events stay off the other relay
+			before := len(other.Events())
+			if _, err := client.SendPublicPost(ctx, "unrelated", "test", nil, 0); err != nil {
+				t.Fatalf("SendPublicPost: %v", err)
+			}
+			if _, err := client.SetProfile(ctx, &ProfileMetadata{Name: "alice"}); err != nil {
+				t.Fatalf("SetProfile: %v", err)
+			}
+			if n := len(other.Events()) - before; n != 0 {
+				t.Errorf("other relay received %d unrelated events", n)
+			}
+		})
+	}
+}
+
+func TestConnectToRelayAdoptsTransientConnection(t *testing.T) {
+	pool, other := newTestRelay(t), newTestRelay(t)
+	ctx := context.Background()
+	client := newTestClient(t, pool.URL)
+
+	if _, err := client.queryOne(ctx, nostr.Filter{Kinds: []int{1}}, other.URL); err != nil {
+		t.Fatalf("queryOne: %v", err)
+	}
+	if len(client.Relays()) != 1 {
+		t.Fatalf("transient relay joined the pool: %v", client.Relays())
+	}
+
+	if err := client.ConnectToRelay(ctx, other.URL); err != nil {
+		t.Fatalf("ConnectToRelay: %v", err)
+	}
+	if len(client.Relays()) != 2 {
+		t.Fatalf("Relays() = %v, want both relays", client.Relays())
+	}
+	if state := client.State(other.URL); state != Connected {
+		t.Errorf("State = %v, want connected", state)
+	}
+}
@@ -0,0 +1,126 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrNotEventAuthor = errors.New("can only delete events we authored")
+	ErrNoEventsGiven  = errors.New("no events to delete")
+)
+
+// DeleteEvents publishes a NIP-09 deletion request (kind 5) for events referenced by hex ID, note1 or nevent1.
+// Every event is looked up first and must have been authored by us. The request is sent to the pool,
+// our NIP-65 write relays and every relay that served one of the originals, with reason as its content.
+func (c *Client) DeleteEvents(ctx context.Context, refs []string, reason string) (*PublishReport, error) {
+	if len(refs) == 0 {
+		return nil, ErrNoEventsGiven
+	}
+
+	ids := []string{}
+	relays := c.Relays()
+	for _, ref := range refs {
+		eventRef, err := ParseEventRef(ref)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, eventRef.ID)
+		relays = append(relays, eventRef.Relays...)
+	}
+	ids = uniqueStrings(ids)
+
+	// Our own write relays are where the originals most likely went
+	c.loadRelayLists(ctx, []string{c.pubKey})
+	own, _ := c.writeRelays.get(c.pubKey)
+	relays = uniqueStrings(append(relays, own...))
+
+	events, holders := c.locateEvents(ctx, ids, relays)
+
+	// Create the event
+	ev := nostr.Event{
+		PubKey:    c.pubKey,
+		CreatedAt: nostr.Now(),
+		Kind:      nostr.KindDeletion,
+		Tags:      nostr.Tags{},
+		Content:   reason,
+	}
+
+	// Reference every event, the addresses of replaceable ones, and each kind once
+	kinds := []string{}
+	for _, id := range ids {
+		target, ok := events[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrEventNotFound, id)
+		}
+		if target.PubKey != c.pubKey {
+			return nil, fmt.Errorf("%w: %s", ErrNotEventAuthor, id)
+		}
+
+		ev.Tags = append(ev.Tags, nostr.Tag{"e", id})
+		if address := addressTag(target); address != "" {
+			ev.Tags = append(ev.Tags, nostr.Tag{"a", address})
+		}
+		kinds = append(kinds, strconv.Itoa(target.Kind))
+	}
+	for _, kind := range uniqueStrings(kinds) {
+		ev.Tags = append(ev.Tags, nostr.Tag{"k", kind})
+	}
+
+	// Sign the event
+	err := c.signer.SignEvent(ctx, &ev)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish the request everywhere the originals were found, on top of our usual relays
+	targets := c.Relays()
+	targets = append(targets, own...)
+	for _, id := range ids {
+		targets = append(targets, holders[id]...)
+	}
+
+	report := (&PublishReport{}).add(c.publish(ctx, ev, uniqueStrings(targets)))
+	return report, report.err()
+}
+
+// locateEvents queries each relay separately for ids, returning the verified events found
+// and the relays that hold each of them
+func (c *Client) locateEvents(ctx context.Context, ids []string, relayURLs []string) (map[string]*nostr.Event, map[string][]string) {
+	results := make([][]*nostr.Event, len(relayURLs))
+
+	var wg sync.WaitGroup
+	for i, relayURL := range relayURLs {
+		wg.Add(1)
+		go func(i int, relayURL string) {
+			defer wg.Done()
+			// Unreachable relays just don't count as holders
+			results[i], _ = c.queryOne(ctx, nostr.Filter{IDs: ids}, relayURL)
+		}(i, relayURL)
+	}
+	wg.Wait()
+
+	events := make(map[string]*nostr.Event)
+	holders := make(map[string][]string)
+	for i, relayURL := range relayURLs {
+		for _, ev := range results[i] {
+			if !ev.CheckID() {
+				continue
+			}
+			if _, ok := events[ev.ID]; !ok {
+				if valid, _ := ev.CheckSignature(); !valid {
+					continue
+				}
+				events[ev.ID] = ev
+			}
+			holders[ev.ID] = append(holders[ev.ID], nostr.NormalizeURL(relayURL))
+		}
+	}
+
+	return events, holders
+}
@@ -0,0 +1,71 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+func TestDeleteEventsRefusesOthersEvents(t *testing.T) {
+	relay := newTestRelay(t)
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+	note := postNote(t, alice, "not yours")
+
+	if _, err := bob.DeleteEvents(context.Background(), []string{note.ID}, ""); !errors.Is(err, ErrNotEventAuthor) {
+		t.Errorf("DeleteEvents error = %v, want ErrNotEventAuthor", err)
+	}
+	if count := kindCount(relay, nostr.KindDeletion); count != 0 {
+		t.Errorf("relay has %d deletion requests, want none", count)
+	}
+}
+
+func TestDeleteEventsReachesHoldingRelays(t *testing.T) {
+	pool := newTestRelay(t)
+	holder := newTestRelay(t)
+	other := newTestRelay(t)
+
+	// The note was published from elsewhere and only lives on holder
+	signer, _ := NewKeySigner(nostr.GeneratePrivateKey())
+	note := postNote(t, newSignerClient(t, signer, holder.URL), "delete me")
+
+	client := newSignerClient(t, signer, pool.URL)
+	ref, _ := nip19.EncodeEvent(note.ID, []string{holder.URL, other.URL}, "")
+	report, err := client.DeleteEvents(context.Background(), []string{ref}, "posted by mistake")
+	if err != nil {
+		t.Fatalf("DeleteEvents: %v", err)
+	}
+
+	// Every relay the request went to is reported, and the relay that was only asked isn't among them
+	results := report.Events[0].Results
+	relays := []string{}
+	for _, result := range results {
+		if !result.Accepted {
+			t.Errorf("%s rejected the request: %s", result.Relay, result.Message)
+		}
+		relays = append(relays, result.Relay)
+	}
+	slices.Sort(relays)
+	want := []string{nostr.NormalizeURL(pool.URL), nostr.NormalizeURL(holder.URL)}
+	slices.Sort(want)
+	if !slices.Equal(relays, want) {
+		t.Errorf("sent to %v, want %v", relays, want)
+	}
+
+	stored := holder.Query(nostr.Filter{Kinds: []int{nostr.KindDeletion}})
+	if len(stored) != 1 {
+		t.Fatalf("holder has %d deletion requests, want 1", len(stored))
+	}
+	deletion := stored[0]
+	if deletion.Content != "posted by mistake" || deletion.Tags.FindWithValue("e", note.ID) == nil ||
+		deletion.Tags.FindWithValue("k", "1") == nil {
+		t.Errorf("deletion = %+v, want the reason, an e tag for the note and k 1", deletion)
+	}
+	if count := kindCount(other, nostr.KindDeletion); count != 0 {
+		t.Errorf("relay without the note got %d deletion requests", count)
+	}
+}
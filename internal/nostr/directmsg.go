@@ -2,11 +2,11 @@ package nostr
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
 	"github.com/nbd-wtf/go-nostr/nip04"
-	"github.com/nbd-wtf/go-nostr/nip19"
 )
 
 // EncryptDirectMessage encrypts a message using NIP-04
@@ -26,29 +26,141 @@ func EncryptDirectMessage(message, recipientPubKey, privateKey string) (string,
 	return encrypted, nil
 }
 
-// SendDirectMessage encrypts and sends a direct message to a recipient
-func SendDirectMessage(ctx context.Context, privateKey, recipientKey, message, relayURL, clientID string) (string, error) {
-	// Get sender's public key
-	pubKey, err := GetPublicKeyFromPrivate(privateKey)
+// DecryptDirectMessage decrypts a NIP-04 message exchanged with the given counterparty
+func DecryptDirectMessage(content, counterpartyPubKey, privateKey string) (string, error) {
+	// Compute shared secret
+	sharedSecret, err := nip04.ComputeSharedSecret(counterpartyPubKey, privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	// Decrypt the message
+	decrypted, err := nip04.Decrypt(content, sharedSecret)
 	if err != nil {
 		return "", err
 	}
 
+	return decrypted, nil
+}
+
+// DirectMessage is a decrypted NIP-04 direct message (kind 4)
+type DirectMessage struct {
+	ID        string
+	Sender    string
+	Recipient string
+	Content   string
+	CreatedAt nostr.Timestamp
+	Event     *nostr.Event
+}
+
+// FetchDirectMessages fetches the kind 4 conversation between us and a peer from the pool
+// and decrypts it, oldest first
+func (c *Client) FetchDirectMessages(ctx context.Context, peerKey string, since time.Time) ([]*DirectMessage, error) {
+	pubKey := c.pubKey
+	peer, err := DecodePublicKey(peerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// One filter for messages we sent the peer, one for messages the peer sent us
+	filters := []nostr.Filter{
+		{Kinds: []int{nostr.KindEncryptedDirectMessage}, Authors: []string{pubKey}, Tags: nostr.TagMap{"p": []string{peer}}},
+		{Kinds: []int{nostr.KindEncryptedDirectMessage}, Authors: []string{peer}, Tags: nostr.TagMap{"p": []string{pubKey}}},
+	}
+	if !since.IsZero() {
+		ts := nostr.Timestamp(since.Unix())
+		for i := range filters {
+			filters[i].Since = &ts
+		}
+	}
+
+	seen := make(map[string]bool)
+	messages := []*DirectMessage{}
+
+	for _, filter := range filters {
+		events, err := c.query(ctx, filter, c.Relays())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ev := range events {
+			// With ourselves as the peer, a message matches both filters
+			if seen[ev.ID] {
+				continue
+			}
+			seen[ev.ID] = true
+
+			msg, err := decryptDirectMessageEvent(ctx, ev, pubKey, c.signer)
+			if err != nil {
+				continue // Skip messages we can't decrypt
+			}
+			// The filters match any p tag, but the message is addressed to the first one
+			if (msg.Sender == pubKey && msg.Recipient != peer) || (msg.Sender == peer && msg.Recipient != pubKey) {
+				continue
+			}
+			messages = append(messages, msg)
+		}
+	}
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].CreatedAt < messages[j].CreatedAt
+	})
+
+	return messages, nil
+}
+
+// decryptDirectMessageEvent decrypts a kind 4 event from either side of the conversation
+func decryptDirectMessageEvent(ctx context.Context, ev *nostr.Event, pubKey string, signer Signer) (*DirectMessage, error) {
+	var recipient string
+	for _, tag := range ev.Tags {
+		if len(tag) >= 2 && tag[0] == "p" {
+			recipient = tag[1]
+			break
+		}
+	}
+	if recipient == "" {
+		return nil, ErrInvalidPublicKey
+	}
+
+	// The shared secret is computed against whoever is on the other side
+	counterparty := ev.PubKey
+	if ev.PubKey == pubKey {
+		counterparty = recipient
+	}
+
+	content, err := signer.NIP04Decrypt(ctx, ev.Content, counterparty)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	return &DirectMessage{
+		ID:        ev.ID,
+		Sender:    ev.PubKey,
+		Recipient: recipient,
+		Content:   content,
+		CreatedAt: ev.CreatedAt,
+		Event:     ev,
+	}, nil
+}
+
+// SendDirectMessage encrypts and sends a direct message to a recipient via the client's pool.
+// A non-zero expiration adds a NIP-40 expiration tag.
+func (c *Client) SendDirectMessage(ctx context.Context, recipientKey, message, clientID string, expiration time.Duration) (*PublishReport, error) {
 	// Decode recipient's key if in NIP-19 format
 	recipientPubKey, err := DecodePublicKey(recipientKey)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Encrypt the message using our NIP-04 implementation
-	encryptedContent, err := EncryptDirectMessage(message, recipientPubKey, privateKey)
+	// Encrypt the message with NIP-04 through the signer
+	encryptedContent, err := c.signer.NIP04Encrypt(ctx, message, recipientPubKey)
 	if err != nil {
-		return "", ErrEncryptionFailed
+		return nil, ErrEncryptionFailed
 	}
 
 	// Create the event
 	ev := nostr.Event{
-		PubKey:    pubKey,
+		PubKey:    c.pubKey,
 		CreatedAt: nostr.Timestamp(time.Now().Unix()),
 		Kind:      nostr.KindEncryptedDirectMessage, // Kind 4 for encrypted DMs
 		Tags:      nostr.Tags{},
@@ -63,30 +175,21 @@ func SendDirectMessage(ctx context.Context, privateKey, recipientKey, message, r
 
 	// not sure are the tags needed for me here :/ but leave it here for now
 
-	// Sign the event
-	err = ev.Sign(privateKey)
-	if err != nil {
-		return "", err
+	// Add expiration tag if requested
+	if expiration > 0 {
+		ev.Tags = append(ev.Tags, expirationTag(expiration))
 	}
 
-	// Connect to relay
-	relay, err := nostr.RelayConnect(ctx, relayURL)
+	// Sign the event
+	err = c.signer.SignEvent(ctx, &ev)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	defer relay.Close()
 
 	// Publish the event
-	err = relay.Publish(ctx, ev)
-	if err != nil {
-		return "", err
+	report, err := c.PublishEvent(ctx, &ev)
+	if expiration > 0 {
+		c.addExpirationWarnings(ctx, report)
 	}
-
-	// Get note ID in bech32 format
-	noteID, err := nip19.EncodeNote(ev.ID)
-	if err != nil {
-		return "", err
-	}
-
-	return noteID, nil
+	return report, err
 }
@@ -0,0 +1,72 @@
+package nostr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestSendDirectMessageRoundTrip(t *testing.T) {
+	relay := newTestRelay(t)
+	alice, bob := newTestClient(t, relay.URL), newTestClient(t, relay.URL)
+	ctx := context.Background()
+
+	report, err := alice.SendDirectMessage(ctx, bob.GetPublicKeyBech32(), "secret", "test", 0)
+	if err != nil {
+		t.Fatalf("SendDirectMessage: %v", err)
+	}
+
+	stored := relay.Query(nostr.Filter{IDs: []string{report.Events[0].EventID}})
+	if len(stored) != 1 {
+		t.Fatal("relay doesn't have the message")
+	}
+	if stored[0].Kind != nostr.KindEncryptedDirectMessage || stored[0].Content == "secret" {
+		t.Errorf("stored message = %+v, want an encrypted kind 4", stored[0])
+	}
+	if stored[0].Tags.FindWithValue("p", bob.GetPublicKey()) == nil {
+		t.Error("message isn't tagged with the recipient")
+	}
+
+	messages, err := bob.FetchDirectMessages(ctx, alice.GetPublicKeyBech32(), time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("FetchDirectMessages: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(messages))
+	}
+	if msg := messages[0]; msg.Content != "secret" || msg.Sender != alice.GetPublicKey() || msg.Recipient != bob.GetPublicKey() {
+		t.Errorf("message = %+v", msg)
+	}
+}
+
+func TestFetchDirectMessagesWithPeer(t *testing.T) {
+	relay := newTestRelay(t)
+	alice, bob, carol := newTestClient(t, relay.URL), newTestClient(t, relay.URL), newTestClient(t, relay.URL)
+	ctx := context.Background()
+
+	send := func(from, to *Client, message string) {
+		t.Helper()
+		if _, err := from.SendDirectMessage(ctx, to.GetPublicKey(), message, "", 0); err != nil {
+			t.Fatalf("SendDirectMessage: %v", err)
+		}
+	}
+	send(alice, bob, "hi bob")
+	send(bob, alice, "hi alice")
+	send(carol, bob, "hi bob, it's carol")
+	send(bob, carol, "hi carol")
+
+	messages, err := bob.FetchDirectMessages(ctx, alice.GetPublicKey(), time.Time{})
+	if err != nil {
+		t.Fatalf("FetchDirectMessages: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want the 2 between Alice and Bob", len(messages))
+	}
+	for _, msg := range messages {
+		if msg.Sender == carol.GetPublicKey() || msg.Recipient == carol.GetPublicKey() {
+			t.Errorf("conversation with Alice includes %q", msg.Content)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DMMode selects the transport used by SendDM
+type DMMode string
+
+const (
+	// DMModeAuto uses NIP-17 when the recipient publishes a kind 10050 relay list and NIP-04 otherwise
+	DMModeAuto DMMode = "auto"
+	// DMModeNIP17 always sends a gift-wrapped kind 14 message
+	DMModeNIP17 DMMode = "nip17"
+	// DMModeNIP04 always sends a kind 4 encrypted direct message
+	DMModeNIP04 DMMode = "nip04"
+)
+
+var (
+	ErrInvalidDMMode = errors.New("invalid DM mode, expected auto, nip17 or nip04")
+)
+
+// ParseDMMode converts a mode name into a DMMode
+func ParseDMMode(mode string) (DMMode, error) {
+	switch DMMode(mode) {
+	case DMModeAuto, DMModeNIP17, DMModeNIP04:
+		return DMMode(mode), nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidDMMode, mode)
+	}
+}
+
+// SendDM sends a direct message to a single recipient using the given mode.
+// The returned report's Transport field says whether NIP-17 or NIP-04 was used.
+// A non-zero expiration adds NIP-40 expiration tags.
+func (c *Client) SendDM(ctx context.Context, recipientKey, message, clientID string, mode DMMode, expiration time.Duration) (*PublishReport, error) {
+	recipientPubKey, err := DecodePublicKey(recipientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == DMModeAuto {
+		mode, err = c.selectDMMode(ctx, recipientPubKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var report *PublishReport
+	switch mode {
+	case DMModeNIP17:
+		report, err = c.SendNIP17DirectMessage(ctx, []string{recipientPubKey}, message, "", "", clientID, expiration)
+	case DMModeNIP04:
+		report, err = c.SendDirectMessage(ctx, recipientPubKey, message, clientID, expiration)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidDMMode, mode)
+	}
+
+	if report != nil {
+		report.Transport = string(mode)
+	}
+	return report, err
+}
+
+// selectDMMode picks NIP-17 if the recipient advertises DM relays, NIP-04 otherwise
+func (c *Client) selectDMMode(ctx context.Context, recipientPubKey string) (DMMode, error) {
+	_, err := c.getPreferredNIP17Relays(ctx, recipientPubKey, c.Relays())
+	switch {
+	case err == nil:
+		return DMModeNIP17, nil
+	case errors.Is(err, ErrNIP17IncompatibleRelay):
+		return DMModeNIP04, nil
+	default:
+		// We couldn't ask any relay, so we can't tell what the recipient supports
+		return "", err
+	}
+}
@@ -0,0 +1,52 @@
+package nostr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestSendDMAutoMode(t *testing.T) {
+	tests := []struct {
+		name         string
+		publishInbox bool
+		want         DMMode
+		// kind is the event kind the message goes out as, unused the other transport's
+		kind, unused int
+	}{
+		{"peer with a DM relay list gets NIP-17", true, DMModeNIP17, 1059, nostr.KindEncryptedDirectMessage},
+		{"peer without one falls back to NIP-04", false, DMModeNIP04, nostr.KindEncryptedDirectMessage, 1059},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			relay := newTestRelay(t)
+			alice := newTestClient(t, relay.URL)
+			bob := newTestClient(t, relay.URL)
+			ctx := context.Background()
+
+			if tt.publishInbox {
+				if _, err := bob.PublishNIP17Preferences(ctx, []string{relay.URL}); err != nil {
+					t.Fatalf("PublishNIP17Preferences: %v", err)
+				}
+			}
+
+			report, err := alice.SendDM(ctx, bob.GetPublicKeyBech32(), "hi bob", "", DMModeAuto, 0)
+			if err != nil {
+				t.Fatalf("SendDM: %v", err)
+			}
+			if report.Transport != string(tt.want) {
+				t.Errorf("transport = %q, want %q", report.Transport, tt.want)
+			}
+
+			// Only the chosen transport was used
+			if count := kindCount(relay, tt.kind); count == 0 {
+				t.Errorf("relay has no kind %d message", tt.kind)
+			}
+			if count := kindCount(relay, tt.unused); count != 0 {
+				t.Errorf("relay has %d kind %d messages, want none", count, tt.unused)
+			}
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 
 var (
 	ErrInvalidKeyFormat    = errors.New("invalid key format")
-	ErrNoRelayConnected    = errors.New("no relay connected, call ConnectToRelay first")
+	ErrNoRelayConnected    = errors.New("no relay in pool, call AddRelay or ConnectToRelay first")
+	ErrIncompleteRead      = errors.New("could not read from every relay")
+	ErrNoSigner            = errors.New("read-only client has no key to sign or decrypt with")
 )
 
 type RelayError struct {
@@ -0,0 +1,117 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+var (
+	ErrInvalidEventRef = errors.New("invalid event reference, expected hex ID, note1 or nevent1")
+	ErrEventNotFound   = errors.New("event not found on any relay")
+)
+
+// EventRef points to an event, with the optional author, kind and relay hints of a nevent
+type EventRef struct {
+	ID     string
+	Author string
+	Kind   int
+	Relays []string
+}
+
+// ParseEventRef parses a hex event ID, note1 or nevent1 reference, with or without a nostr: prefix
+func ParseEventRef(ref string) (*EventRef, error) {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "nostr:")
+
+	if nostr.IsValid32ByteHex(ref) {
+		return &EventRef{ID: ref}, nil
+	}
+
+	prefix, decoded, err := nip19.Decode(ref)
+	if err != nil {
+		return nil, ErrInvalidEventRef
+	}
+
+	switch prefix {
+	case "note":
+		return &EventRef{ID: decoded.(string)}, nil
+
+	case "nevent":
+		pointer, ok := decoded.(nostr.EventPointer)
+		if !ok {
+			return nil, ErrInvalidEventRef
+		}
+		return &EventRef{
+			ID:     pointer.ID,
+			Author: pointer.Author,
+			Kind:   pointer.Kind,
+			Relays: pointer.Relays,
+		}, nil
+	}
+
+	return nil, ErrInvalidEventRef
+}
+
+// FetchEvent looks up a referenced event on the pool and the reference's relay hints.
+// The returned event's ID and signature are verified.
+func (c *Client) FetchEvent(ctx context.Context, ref string) (*nostr.Event, error) {
+	eventRef, err := ParseEventRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := nostr.Filter{IDs: []string{eventRef.ID}}
+	if eventRef.Author != "" {
+		filter.Authors = []string{eventRef.Author}
+	}
+
+	events, err := c.query(ctx, filter, uniqueStrings(append(c.Relays(), eventRef.Relays...)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ev := range events {
+		if ev.ID == eventRef.ID && validEvent(ev) {
+			return ev, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrEventNotFound, eventRef.ID)
+}
+
+// validEvent reports whether an event's ID matches its content and its signature verifies
+func validEvent(ev *nostr.Event) bool {
+	if !ev.CheckID() {
+		return false
+	}
+	ok, _ := ev.CheckSignature()
+	return ok
+}
+
+// eventRelayHint returns a relay to mention alongside a reference to an event: the reference's
+// first relay hint if it has one, otherwise the first relay of the pool in sorted order, or ""
+func (c *Client) eventRelayHint(ref string) string {
+	if eventRef, err := ParseEventRef(ref); err == nil && len(eventRef.Relays) > 0 {
+		return eventRef.Relays[0]
+	}
+	if relays := c.Relays(); len(relays) > 0 {
+		return relays[0]
+	}
+	return ""
+}
+
+// addressTag returns the NIP-01 "a" tag value (kind:pubkey:d) of an addressable or replaceable event, or ""
+func addressTag(ev *nostr.Event) string {
+	switch {
+	case nostr.IsAddressableKind(ev.Kind):
+		return fmt.Sprintf("%d:%s:%s", ev.Kind, ev.PubKey, ev.Tags.GetD())
+	case nostr.IsReplaceableKind(ev.Kind):
+		return fmt.Sprintf("%d:%s:", ev.Kind, ev.PubKey)
+	default:
+		return ""
+	}
+}
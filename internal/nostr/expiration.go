@@ -0,0 +1,118 @@
+package nostr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip11"
+)
+
+// expirationTag returns a NIP-40 expiration tag for an event that should disappear after d
+func expirationTag(d time.Duration) nostr.Tag {
+	return nostr.Tag{"expiration", strconv.FormatInt(time.Now().Add(d).Unix(), 10)}
+}
+
+// addExpirationWarnings notes every relay in the report whose NIP-11 document doesn't list NIP-40,
+// since those relays may keep serving the event after it expires
+func (c *Client) addExpirationWarnings(ctx context.Context, report *PublishReport) {
+	if report == nil {
+		return
+	}
+
+	relaySet := make(map[string]bool)
+	for _, event := range report.Events {
+		for _, result := range event.Results {
+			relaySet[result.Relay] = true
+		}
+	}
+
+	relayURLs := make([]string, 0, len(relaySet))
+	for relayURL := range relaySet {
+		relayURLs = append(relayURLs, relayURL)
+	}
+	sort.Strings(relayURLs)
+
+	warnings := make([]string, len(relayURLs))
+	var wg sync.WaitGroup
+	for i, relayURL := range relayURLs {
+		wg.Add(1)
+		go func(i int, relayURL string) {
+			defer wg.Done()
+
+			info, err := c.relayInfo.fetch(ctx, relayURL)
+			if err != nil {
+				warnings[i] = fmt.Sprintf("%s: could not fetch NIP-11 document, expiration may not be honored", relayURL)
+			} else if !supportsNIP(info, 40) {
+				warnings[i] = fmt.Sprintf("%s: does not list NIP-40, expiration may not be honored", relayURL)
+			}
+		}(i, relayURL)
+	}
+	wg.Wait()
+
+	for _, warning := range warnings {
+		if warning != "" {
+			report.Warnings = append(report.Warnings, warning)
+		}
+	}
+}
+
+// relayInfoCache remembers the NIP-11 documents of relays, so sending several expiring
+// messages doesn't fetch them again. Failed fetches are not cached.
+type relayInfoCache struct {
+	mu   sync.Mutex
+	docs map[string]nip11.RelayInformationDocument
+}
+
+func newRelayInfoCache() *relayInfoCache {
+	return &relayInfoCache{docs: make(map[string]nip11.RelayInformationDocument)}
+}
+
+// fetch returns a relay's cached NIP-11 document, fetching it on first use
+func (rc *relayInfoCache) fetch(ctx context.Context, relayURL string) (nip11.RelayInformationDocument, error) {
+	relayURL = nostr.NormalizeURL(relayURL)
+
+	rc.mu.Lock()
+	info, ok := rc.docs[relayURL]
+	rc.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	info, err := nip11.Fetch(ctx, relayURL)
+	if err != nil {
+		return info, err
+	}
+
+	rc.mu.Lock()
+	rc.docs[relayURL] = info
+	rc.mu.Unlock()
+
+	return info, nil
+}
+
+// supportsNIP reports whether a relay information document lists a NIP.
+// Relays encode supported_nips as numbers or, occasionally, strings.
+func supportsNIP(info nip11.RelayInformationDocument, nip int) bool {
+	for _, supported := range info.SupportedNIPs {
+		switch n := supported.(type) {
+		case float64:
+			if int(n) == nip {
+				return true
+			}
+		case int:
+			if n == nip {
+				return true
+			}
+		case string:
+			if n == strconv.Itoa(nip) {
+				return true
+			}
+		}
+	}
+	return false
+}
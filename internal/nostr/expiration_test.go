@@ -0,0 +1,77 @@
+package nostr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestExpirationWarningsCacheRelayInfo(t *testing.T) {
+	relay := newTestRelay(t)
+	client := newTestClient(t, relay.URL)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		report, err := client.SendPublicPost(ctx, "gone tomorrow", "", nil, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("SendPublicPost: %v", err)
+		}
+
+		// relaytest doesn't list NIP-40
+		if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "NIP-40") {
+			t.Errorf("warnings = %v, want one about NIP-40", report.Warnings)
+		}
+	}
+
+	if fetches := relay.InfoFetches(); fetches != 1 {
+		t.Errorf("NIP-11 document fetched %d times, want 1", fetches)
+	}
+}
+
+func TestExpirationWarningsSkipNIP40Relays(t *testing.T) {
+	relay := newTestRelay(t)
+	relay.Info.SupportedNIPs = []any{1, 11, 40}
+	client := newTestClient(t, relay.URL)
+
+	report, err := client.SendPublicPost(context.Background(), "gone tomorrow", "", nil, 24*time.Hour)
+	if err != nil {
+		t.Fatalf("SendPublicPost: %v", err)
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("warnings = %v, want none", report.Warnings)
+	}
+}
+
+func TestSendNIP17FileExpiration(t *testing.T) {
+	relay := newTestRelay(t)
+	server := newBlobServer(t, "blossom")
+	ctx := context.Background()
+
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("self-destructing"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	uploader := NewBlossomUploader(server.URL, alice.Signer())
+	if _, err := alice.SendNIP17File(ctx, []string{bob.GetPublicKeyBech32()}, path, uploader, "", "", "", time.Hour); err != nil {
+		t.Fatalf("SendNIP17File: %v", err)
+	}
+
+	wraps := relay.Query(nostr.Filter{Kinds: []int{1059}})
+	if len(wraps) != 2 {
+		t.Fatalf("relay has %d gift wraps, want 2", len(wraps))
+	}
+	for _, wrap := range wraps {
+		if wrap.Tags.Find("expiration") == nil {
+			t.Errorf("gift wrap for %s has no expiration tag", wrap.Tags.GetFirst([]string{"p", ""}).Value())
+		}
+	}
+}
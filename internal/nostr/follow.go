@@ -0,0 +1,178 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrNotFollowing = errors.New("not following any of the given keys")
+)
+
+// Follow is an entry of a kind 3 contact list
+type Follow struct {
+	PubKey  string `json:"pubkey"`
+	Relay   string `json:"relay,omitempty"`
+	Petname string `json:"petname,omitempty"`
+}
+
+// followFromTag parses a ["p", <pubkey>, <relay>, <petname>] tag
+func followFromTag(tag nostr.Tag) (Follow, bool) {
+	if len(tag) < 2 || tag[0] != "p" || !nostr.IsValidPublicKey(tag[1]) {
+		return Follow{}, false
+	}
+
+	follow := Follow{PubKey: tag[1]}
+	if len(tag) >= 3 {
+		follow.Relay = tag[2]
+	}
+	if len(tag) >= 4 {
+		follow.Petname = tag[3]
+	}
+	return follow, true
+}
+
+// tag encodes a follow as a p tag, omitting trailing empty fields
+func (f Follow) tag() nostr.Tag {
+	tag := nostr.Tag{"p", f.PubKey, f.Relay, f.Petname}
+	for len(tag) > 2 && tag[len(tag)-1] == "" {
+		tag = tag[:len(tag)-1]
+	}
+	return tag
+}
+
+// ListFollows returns the newest contact list of a public key (hex, npub, or nprofile).
+// An empty pubKey lists our own follows.
+func (c *Client) ListFollows(ctx context.Context, pubKey string) ([]Follow, error) {
+	if pubKey == "" {
+		pubKey = c.pubKey
+	}
+	pubKeyHex, err := DecodePublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := c.fetchLatest(ctx, nostr.KindFollowList, pubKeyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	follows := []Follow{}
+	if list == nil {
+		return follows, nil
+	}
+	for _, tag := range list.Tags {
+		if follow, ok := followFromTag(tag); ok {
+			follows = append(follows, follow)
+		}
+	}
+	return follows, nil
+}
+
+// Follow adds keys to our contact list, or updates the relay hint and petname of keys already in it.
+// The newest list is fetched from every relay first so a newer list is never overwritten.
+func (c *Client) Follow(ctx context.Context, follows []Follow) (*PublishReport, error) {
+	// Decode keys before touching the list
+	decoded := make([]Follow, 0, len(follows))
+	for _, follow := range follows {
+		pubKey, err := DecodePublicKey(follow.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		follow.PubKey = pubKey
+		decoded = append(decoded, follow)
+	}
+
+	return c.editContactList(ctx, func(tags nostr.Tags) (nostr.Tags, bool) {
+		for _, follow := range decoded {
+			index := -1
+			for i, tag := range tags {
+				if existing, ok := followFromTag(tag); ok && existing.PubKey == follow.PubKey {
+					index = i
+					// Keep hints we weren't asked to change
+					if follow.Relay == "" {
+						follow.Relay = existing.Relay
+					}
+					if follow.Petname == "" {
+						follow.Petname = existing.Petname
+					}
+					break
+				}
+			}
+
+			if index >= 0 {
+				tags[index] = follow.tag()
+			} else {
+				tags = append(tags, follow.tag())
+			}
+		}
+		return tags, true
+	})
+}
+
+// Unfollow removes keys (hex, npub, or nprofile) from our contact list.
+// It returns ErrNotFollowing without publishing if none of them were followed.
+func (c *Client) Unfollow(ctx context.Context, pubKeys []string) (*PublishReport, error) {
+	remove := make(map[string]bool)
+	for _, pubKey := range pubKeys {
+		pubKeyHex, err := DecodePublicKey(pubKey)
+		if err != nil {
+			return nil, err
+		}
+		remove[pubKeyHex] = true
+	}
+
+	return c.editContactList(ctx, func(tags nostr.Tags) (nostr.Tags, bool) {
+		kept := nostr.Tags{}
+		for _, tag := range tags {
+			if follow, ok := followFromTag(tag); ok && remove[follow.PubKey] {
+				continue
+			}
+			kept = append(kept, tag)
+		}
+		return kept, len(kept) != len(tags)
+	})
+}
+
+// editContactList applies edit to the tags of our newest kind 3 event and publishes the result.
+// Content and tags other than follows are preserved. edit returns false if nothing changed.
+// It fails with ErrIncompleteRead, without publishing, if any relay in the pool can't be read.
+func (c *Client) editContactList(ctx context.Context, edit func(nostr.Tags) (nostr.Tags, bool)) (*PublishReport, error) {
+	// Read strictly (see queryStrict): publishing a list we only partly saw would wipe follows
+	current, err := c.fetchLatestStrict(ctx, nostr.KindFollowList, c.pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := nostr.Tags{}
+	content := ""
+	if current != nil {
+		tags = append(tags, current.Tags...)
+		content = current.Content
+	}
+
+	tags, changed := edit(tags)
+	if !changed {
+		return nil, ErrNotFollowing
+	}
+
+	// Create the event
+	ev := nostr.Event{
+		PubKey:    c.pubKey,
+		CreatedAt: replacementTimestamp(current),
+		Kind:      nostr.KindFollowList,
+		Tags:      tags,
+		Content:   content,
+	}
+
+	// Sign the event
+	err = c.signer.SignEvent(ctx, &ev)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish the event
+	return c.PublishEvent(ctx, &ev)
+}
@@ -0,0 +1,28 @@
+package nostr
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFollowKeepsExistingFollows(t *testing.T) {
+	relay := newTestRelay(t)
+	client := newTestClient(t, relay.URL)
+	ctx := context.Background()
+	first, second := newTestClient(t).GetPublicKey(), newTestClient(t).GetPublicKey()
+
+	if _, err := client.Follow(ctx, []Follow{{PubKey: first, Petname: "first"}}); err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+	if _, err := client.Follow(ctx, []Follow{{PubKey: second}}); err != nil {
+		t.Fatalf("Follow: %v", err)
+	}
+
+	follows, err := client.ListFollows(ctx, "")
+	if err != nil {
+		t.Fatalf("ListFollows: %v", err)
+	}
+	if len(follows) != 2 || follows[0].Petname != "first" || follows[1].PubKey != second {
+		t.Errorf("follows = %+v, want both keys", follows)
+	}
+}
@@ -0,0 +1,67 @@
+package nostr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/relaytest"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// newTestRelay starts an in-process relay that is closed when the test ends
+func newTestRelay(t *testing.T) *relaytest.Relay {
+	t.Helper()
+	relay := relaytest.NewRelay()
+	t.Cleanup(relay.Close)
+	return relay
+}
+
+// newTestClient creates a client with a fresh key and the given relays in its pool
+func newTestClient(t *testing.T, relayURLs ...string) *Client {
+	t.Helper()
+	signer, err := NewKeySigner(nostr.GeneratePrivateKey())
+	if err != nil {
+		t.Fatalf("NewKeySigner: %v", err)
+	}
+	return newSignerClient(t, signer, relayURLs...)
+}
+
+// newSignerClient creates a client for an existing signer with the given relays in its pool
+func newSignerClient(t *testing.T, signer Signer, relayURLs ...string) *Client {
+	t.Helper()
+	client, err := NewClient(signer, 5*time.Second)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	for _, url := range relayURLs {
+		client.AddRelay(url)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// kindCount returns how many events of a kind a relay stores
+func kindCount(relay *relaytest.Relay, kind int) int {
+	return len(relay.Query(nostr.Filter{Kinds: []int{kind}}))
+}
+
+// postNote publishes a kind 1 note and returns it as stored by the relay
+func postNote(t *testing.T, client *Client, content string) *nostr.Event {
+	t.Helper()
+	report, err := client.SendPublicPost(context.Background(), content, "", nil, 0)
+	if err != nil {
+		t.Fatalf("SendPublicPost: %v", err)
+	}
+	return fetchByID(t, client, report.Events[0].EventID)
+}
+
+// fetchByID fetches a stored event through client
+func fetchByID(t *testing.T, client *Client, id string) *nostr.Event {
+	t.Helper()
+	event, err := client.FetchEvent(context.Background(), id)
+	if err != nil {
+		t.Fatalf("FetchEvent: %v", err)
+	}
+	return event
+}
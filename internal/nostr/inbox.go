@@ -0,0 +1,185 @@
+package nostr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrNotGiftWrap      = errors.New("event is not a gift wrap")
+	ErrInvalidSeal      = errors.New("invalid seal")
+	ErrSealSignature    = errors.New("seal signature verification failed")
+	ErrSenderMismatch   = errors.New("seal pubkey does not match message pubkey")
+	ErrUnsupportedRumor = errors.New("unsupported sealed message kind")
+)
+
+// giftWrapTimeSkew is how far back gift wrap and seal timestamps may be randomized
+const giftWrapTimeSkew = 2 * 24 * time.Hour
+
+// NIP17Message is a decrypted NIP-17 chat message received inside a gift wrap
+type NIP17Message struct {
+	ID         string
+	WrapID     string
+	Sender     string
+	Recipients []string
+	Content    string
+	Subject    string
+	ReplyTo    string
+	File       *FileAttachment // Set for kind 15 file messages
+	CreatedAt  nostr.Timestamp
+	Event      *nostr.Event
+}
+
+// unwrapGiftWrap decrypts a gift wrap (kind 1059) and its seal (kind 13) and returns the inner rumor
+func unwrapGiftWrap(ctx context.Context, wrap *nostr.Event, signer Signer) (*nostr.Event, error) {
+	if wrap.Kind != 1059 {
+		return nil, ErrNotGiftWrap
+	}
+
+	// Decrypt the gift wrap with the ephemeral key it was signed with
+	sealJSON, err := signer.NIP44Decrypt(ctx, wrap.Content, wrap.PubKey)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	var seal nostr.Event
+	if err := json.Unmarshal([]byte(sealJSON), &seal); err != nil {
+		return nil, ErrInvalidSeal
+	}
+	if seal.Kind != 13 {
+		return nil, ErrInvalidSeal
+	}
+
+	// The seal is the only layer signed by the real sender
+	ok, err := seal.CheckSignature()
+	if err != nil || !ok {
+		return nil, ErrSealSignature
+	}
+
+	// Decrypt the seal with the sender's key
+	rumorJSON, err := signer.NIP44Decrypt(ctx, seal.Content, seal.PubKey)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	var rumor nostr.Event
+	if err := json.Unmarshal([]byte(rumorJSON), &rumor); err != nil {
+		return nil, ErrInvalidSeal
+	}
+
+	// Prevent impersonation: the rumor must be authored by whoever signed the seal
+	if rumor.PubKey != seal.PubKey {
+		return nil, ErrSenderMismatch
+	}
+
+	// Rumors are unsigned, so a sent ID proves nothing. Recompute it so dedupeMessages
+	// can't be fooled into dropping a different message that claims the same ID.
+	rumor.ID = rumor.GetID()
+
+	return &rumor, nil
+}
+
+// messageFromRumor converts an unwrapped kind 14 or 15 rumor into a NIP17Message
+func messageFromRumor(rumor *nostr.Event, wrapID string) (*NIP17Message, error) {
+	if rumor.Kind != 14 && rumor.Kind != 15 {
+		return nil, ErrUnsupportedRumor
+	}
+
+	msg := &NIP17Message{
+		ID:        rumor.ID,
+		WrapID:    wrapID,
+		Sender:    rumor.PubKey,
+		Content:   rumor.Content,
+		CreatedAt: rumor.CreatedAt,
+		Event:     rumor,
+	}
+
+	// File messages carry the URL as content and the decryption details in tags
+	if rumor.Kind == 15 {
+		msg.File = fileAttachmentFromTags(rumor.Content, rumor.Tags)
+	}
+
+	for _, tag := range rumor.Tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "p":
+			// Older senders may have put NIP-19 keys in p tags
+			if pubKey, err := DecodePublicKey(tag[1]); err == nil {
+				msg.Recipients = append(msg.Recipients, pubKey)
+			}
+		case "e":
+			msg.ReplyTo = tag[1]
+		case "subject":
+			msg.Subject = tag[1]
+		}
+	}
+
+	return msg, nil
+}
+
+// FetchNIP17Messages fetches gift wraps addressed to us from the pool and returns the decrypted chat messages
+func (c *Client) FetchNIP17Messages(ctx context.Context, since time.Time) ([]*NIP17Message, error) {
+	filter := nostr.Filter{
+		Kinds: []int{1059},
+		Tags:  nostr.TagMap{"p": []string{c.pubKey}},
+	}
+	if !since.IsZero() {
+		// Gift wrap timestamps are randomized into the past, so widen the window
+		ts := nostr.Timestamp(since.Add(-giftWrapTimeSkew).Unix())
+		filter.Since = &ts
+	}
+
+	wraps, err := c.query(ctx, filter, c.Relays())
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []*NIP17Message{}
+	for _, wrap := range wraps {
+		rumor, err := unwrapGiftWrap(ctx, wrap, c.signer)
+		if err != nil {
+			continue // Skip wraps we can't open or that fail verification
+		}
+
+		msg, err := messageFromRumor(rumor, wrap.ID)
+		if err != nil {
+			continue
+		}
+
+		if !since.IsZero() && msg.CreatedAt.Time().Before(since) {
+			continue
+		}
+
+		messages = append(messages, msg)
+	}
+
+	// The same rumor arrives once per recipient copy, keep the first one
+	messages = dedupeMessages(messages)
+
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].CreatedAt < messages[j].CreatedAt
+	})
+
+	return messages, nil
+}
+
+// dedupeMessages drops repeated rumors that were delivered in more than one gift wrap
+func dedupeMessages(messages []*NIP17Message) []*NIP17Message {
+	seen := make(map[string]bool)
+	unique := messages[:0]
+	for _, msg := range messages {
+		if seen[msg.ID] {
+			continue
+		}
+		seen[msg.ID] = true
+		unique = append(unique, msg)
+	}
+	return unique
+}
@@ -1,15 +1,41 @@
 package nostr
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip06"
 	"github.com/nbd-wtf/go-nostr/nip19"
+	"github.com/nbd-wtf/go-nostr/nip49"
+	"github.com/tyler-smith/go-bip32"
+	"github.com/tyler-smith/go-bip39"
 )
 
-// DeterminePrivateKey returns the hex private key from either nsec or hex format
+// DefaultScryptLogN is the NIP-49 scrypt work factor (2^16 rounds) used when encrypting keys
+const DefaultScryptLogN = 16
+
+// MinScryptLogN and MaxScryptLogN bound the accepted work factor: below 16 is too weak,
+// above 22 needs several gigabytes of memory to decrypt
+const (
+	MinScryptLogN = 16
+	MaxScryptLogN = 22
+)
+
+var (
+	ErrEncryptedKey      = errors.New("key is encrypted (ncryptsec), a passphrase is required")
+	ErrInvalidMnemonic   = errors.New("invalid BIP-39 mnemonic")
+	ErrInvalidScryptLogN = fmt.Errorf("scrypt work factor must be between %d and %d", MinScryptLogN, MaxScryptLogN)
+)
+
+// DeterminePrivateKey returns the hex private key from either nsec or hex format.
+// Encrypted ncryptsec keys return ErrEncryptedKey and must go through DecryptPrivateKey.
 func DeterminePrivateKey(privateKeyHex, nsecKey string) (string, error) {
-	if nsecKey != "" {
+	if IsEncryptedKey(nsecKey) {
+		return "", ErrEncryptedKey
+	} else if nsecKey != "" {
 		// Convert nsec to hex
 		prefix, decoded, err := nip19.Decode(nsecKey)
 		if err != nil {
@@ -65,3 +91,155 @@ func DecodePublicKey(pubKey string) (string, error) {
 func FormatPublicKey(pubKeyHex string) (string, error) {
 	return nip19.EncodePublicKey(pubKeyHex)
 }
+
+// FormatPrivateKey converts a hex private key to bech32 nsec format
+func FormatPrivateKey(privateKeyHex string) (string, error) {
+	return nip19.EncodePrivateKey(privateKeyHex)
+}
+
+// IsEncryptedKey reports whether a key is a NIP-49 ncryptsec string
+func IsEncryptedKey(key string) bool {
+	return strings.HasPrefix(key, "ncryptsec1")
+}
+
+// EncryptPrivateKey encrypts a hex private key with a passphrase into a NIP-49 ncryptsec string
+func EncryptPrivateKey(privateKeyHex, passphrase string, logN uint8) (string, error) {
+	if _, err := GetPublicKeyFromPrivate(privateKeyHex); err != nil {
+		return "", ErrInvalidPrivateKey
+	}
+	if logN == 0 {
+		logN = DefaultScryptLogN
+	}
+	if logN < MinScryptLogN || logN > MaxScryptLogN {
+		return "", ErrInvalidScryptLogN
+	}
+
+	return nip49.Encrypt(privateKeyHex, passphrase, logN, nip49.ClientDoesNotTrackThisData)
+}
+
+// DecryptPrivateKey decrypts a NIP-49 ncryptsec string into a hex private key
+func DecryptPrivateKey(ncryptsec, passphrase string) (string, error) {
+	if !IsEncryptedKey(ncryptsec) {
+		return "", ErrInvalidKeyFormat
+	}
+
+	privateKey, err := nip49.Decrypt(ncryptsec, passphrase)
+	if err != nil {
+		return "", ErrDecryptionFailed
+	}
+
+	return privateKey, nil
+}
+
+// GeneratePrivateKey returns a new random private key in hex format
+func GeneratePrivateKey() string {
+	return nostr.GeneratePrivateKey()
+}
+
+// GenerateMnemonic returns a new 24-word BIP-39 mnemonic
+func GenerateMnemonic() (string, error) {
+	return nip06.GenerateSeedWords()
+}
+
+// PrivateKeyFromMnemonic derives the NIP-06 key at m/44'/1237'/<account>'/0/0 from a BIP-39 mnemonic.
+// passphrase is the optional BIP-39 passphrase, not a NIP-49 one.
+func PrivateKeyFromMnemonic(mnemonic, passphrase string, account uint32) (string, error) {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	if !nip06.ValidateWords(mnemonic) {
+		return "", ErrInvalidMnemonic
+	}
+
+	key, err := bip32.NewMasterKey(bip39.NewSeed(mnemonic, passphrase))
+	if err != nil {
+		return "", err
+	}
+
+	derivationPath := []uint32{
+		bip32.FirstHardenedChild + 44,
+		bip32.FirstHardenedChild + 1237,
+		bip32.FirstHardenedChild + account,
+		0,
+		0,
+	}
+
+	for _, index := range derivationPath {
+		if key, err = key.NewChildKey(index); err != nil {
+			return "", err
+		}
+	}
+
+	return hex.EncodeToString(key.Key), nil
+}
+
+// FormatProfile encodes a hex public key and relay hints as a bech32 nprofile
+func FormatProfile(pubKeyHex string, relays []string) (string, error) {
+	return nip19.EncodeProfile(pubKeyHex, relays)
+}
+
+// KeyInfo lists every encoding of a key. Private fields are empty when only a public key is known.
+type KeyInfo struct {
+	PrivateKey string
+	Nsec       string
+	PublicKey  string
+	Npub       string
+	Nprofile   string
+}
+
+// InspectKey decodes a hex, nsec, npub or nprofile key and returns all of its encodings.
+// A bare hex key is treated as a private key only when hexIsPrivate is set.
+func InspectKey(key string, relays []string, hexIsPrivate bool) (*KeyInfo, error) {
+	info := &KeyInfo{}
+
+	switch {
+	case strings.HasPrefix(key, "nsec1"):
+		privateKey, err := DeterminePrivateKey("", key)
+		if err != nil {
+			return nil, err
+		}
+		info.PrivateKey = privateKey
+
+	case len(key) == 64 && hexIsPrivate:
+		info.PrivateKey = key
+
+	default:
+		pubKey, err := DecodePublicKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if !nostr.IsValidPublicKey(pubKey) {
+			return nil, ErrInvalidPublicKey
+		}
+		info.PublicKey = pubKey
+
+		// Keep the relay hints an nprofile came with unless new ones were given
+		if strings.HasPrefix(key, "nprofile1") && len(relays) == 0 {
+			if _, decoded, err := nip19.Decode(key); err == nil {
+				if pointer, ok := decoded.(nostr.ProfilePointer); ok {
+					relays = pointer.Relays
+				}
+			}
+		}
+	}
+
+	if info.PrivateKey != "" {
+		pubKey, err := GetPublicKeyFromPrivate(info.PrivateKey)
+		if err != nil {
+			return nil, ErrInvalidPrivateKey
+		}
+		info.PublicKey = pubKey
+
+		if info.Nsec, err = FormatPrivateKey(info.PrivateKey); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if info.Npub, err = FormatPublicKey(info.PublicKey); err != nil {
+		return nil, err
+	}
+	if info.Nprofile, err = FormatProfile(info.PublicKey, relays); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
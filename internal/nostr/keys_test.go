@@ -0,0 +1,147 @@
+package nostr
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+func TestEncryptPrivateKeyRoundTrip(t *testing.T) {
+	privateKey := GeneratePrivateKey()
+
+	ncryptsec, err := EncryptPrivateKey(privateKey, "passphrase", 0)
+	if err != nil {
+		t.Fatalf("EncryptPrivateKey: %v", err)
+	}
+	if !IsEncryptedKey(ncryptsec) {
+		t.Fatalf("EncryptPrivateKey returned %q, want an ncryptsec", ncryptsec)
+	}
+
+	decrypted, err := DecryptPrivateKey(ncryptsec, "passphrase")
+	if err != nil {
+		t.Fatalf("DecryptPrivateKey: %v", err)
+	}
+	if decrypted != privateKey {
+		t.Error("decrypted key differs from the original")
+	}
+
+	if _, err := DecryptPrivateKey(ncryptsec, "wrong"); !errors.Is(err, ErrDecryptionFailed) {
+		t.Errorf("wrong passphrase error = %v, want ErrDecryptionFailed", err)
+	}
+}
+
+func TestEncryptPrivateKeyRejectsLogN(t *testing.T) {
+	for _, logN := range []uint8{1, MinScryptLogN - 1, MaxScryptLogN + 1, 255} {
+		if _, err := EncryptPrivateKey(GeneratePrivateKey(), "passphrase", logN); !errors.Is(err, ErrInvalidScryptLogN) {
+			t.Errorf("logN %d: error = %v, want ErrInvalidScryptLogN", logN, err)
+		}
+	}
+}
+
+// The test vectors published in NIP-06
+func TestPrivateKeyFromMnemonicVectors(t *testing.T) {
+	tests := []struct {
+		mnemonic   string
+		privateKey string
+		nsec       string
+		publicKey  string
+		npub       string
+	}{
+		{
+			mnemonic:   "leader monkey parrot ring guide accident before fence cannon height naive bean",
+			privateKey: "7f7ff03d123792d6ac594bfa67bf6d0c0ab55b6b1fdb6249303fe861f1ccba9a",
+			nsec:       "nsec10allq0gjx7fddtzef0ax00mdps9t2kmtrldkyjfs8l5xruwvh2dq0lhhkp",
+			publicKey:  "17162c921dc4d2518f9a101db33695df1afb56ab82f5ff3e5da6eec3ca5cd917",
+			npub:       "npub1zutzeysacnf9rru6zqwmxd54mud0k44tst6l70ja5mhv8jjumytsd2x7nu",
+		},
+		{
+			mnemonic:   "what bleak badge arrange retreat wolf trade produce cricket blur garlic valid proud rude strong choose busy staff weather area salt hollow arm fade",
+			privateKey: "c15d739894c81a2fcfd3a2df85a0d2c0dbc47a280d092799f144d73d7ae78add",
+			nsec:       "nsec1c9wh8xy5eqdzln7n5t0ctgxjcrdug73gp5yj0x03gntn67h83twssdfhel",
+			publicKey:  "d41b22899549e1f3d335a31002cfd382174006e166d3e658e3a5eecdb6463573",
+			npub:       "npub16sdj9zv4f8sl85e45vgq9n7nsgt5qphpvmf7vk8r5hhvmdjxx4es8rq74h",
+		},
+	}
+
+	for _, tt := range tests {
+		privateKey, err := PrivateKeyFromMnemonic(tt.mnemonic, "", 0)
+		if err != nil {
+			t.Fatalf("PrivateKeyFromMnemonic: %v", err)
+		}
+		if privateKey != tt.privateKey {
+			t.Errorf("private key = %s, want %s", privateKey, tt.privateKey)
+		}
+
+		info, err := InspectKey(privateKey, nil, true)
+		if err != nil {
+			t.Fatalf("InspectKey: %v", err)
+		}
+		if info.Nsec != tt.nsec || info.PublicKey != tt.publicKey || info.Npub != tt.npub {
+			t.Errorf("InspectKey = %+v, want %s %s %s", info, tt.nsec, tt.publicKey, tt.npub)
+		}
+	}
+
+	if _, err := PrivateKeyFromMnemonic("leader monkey parrot", "", 0); !errors.Is(err, ErrInvalidMnemonic) {
+		t.Errorf("short mnemonic error = %v, want ErrInvalidMnemonic", err)
+	}
+}
+
+func TestInspectKeyRoundTrip(t *testing.T) {
+	privateKey := GeneratePrivateKey()
+	publicKey, _ := nostr.GetPublicKey(privateKey)
+	nsec, _ := nip19.EncodePrivateKey(privateKey)
+	npub, _ := nip19.EncodePublicKey(publicKey)
+	relays := []string{"wss://relay.example", "wss://other.example"}
+	nprofile, _ := nip19.EncodeProfile(publicKey, relays)
+	bare, _ := nip19.EncodeProfile(publicKey, nil)
+
+	tests := []struct {
+		name    string
+		key     string
+		relays  []string
+		private bool
+		// wantRelays are the hints the returned nprofile carries
+		wantRelays []string
+	}{
+		{name: "nsec", key: nsec, private: true},
+		{name: "hex private key", key: privateKey, private: true},
+		{name: "npub", key: npub},
+		{name: "hex public key", key: publicKey},
+		{name: "npub with relays", key: npub, relays: relays, wantRelays: relays},
+		{name: "nprofile keeps its relays", key: nprofile, wantRelays: relays},
+		{name: "nprofile with new relays", key: nprofile, relays: relays[:1], wantRelays: relays[:1]},
+		{name: "nprofile without relays", key: bare},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := InspectKey(tt.key, tt.relays, tt.private)
+			if err != nil {
+				t.Fatalf("InspectKey: %v", err)
+			}
+
+			if info.PublicKey != publicKey || info.Npub != npub {
+				t.Errorf("public key = %s %s, want %s %s", info.PublicKey, info.Npub, publicKey, npub)
+			}
+			if tt.private && (info.PrivateKey != privateKey || info.Nsec != nsec) {
+				t.Errorf("private key = %s %s, want %s %s", info.PrivateKey, info.Nsec, privateKey, nsec)
+			}
+			if !tt.private && (info.PrivateKey != "" || info.Nsec != "") {
+				t.Error("a public key produced private encodings")
+			}
+
+			_, decoded, err := nip19.Decode(info.Nprofile)
+			pointer, _ := decoded.(nostr.ProfilePointer)
+			if err != nil || pointer.PublicKey != publicKey || !slices.Equal(pointer.Relays, tt.wantRelays) {
+				t.Errorf("nprofile decodes to %+v, want %s with relays %v", pointer, publicKey, tt.wantRelays)
+			}
+		})
+	}
+
+	if _, err := InspectKey("npub1nope", nil, false); err == nil {
+		t.Error("InspectKey accepted an invalid npub")
+	}
+}
@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
-	"github.com/nbd-wtf/go-nostr/nip19"
 	"github.com/nbd-wtf/go-nostr/nip44"
 )
 
@@ -40,7 +39,8 @@ func randomTimePast() nostr.Timestamp {
 	return nostr.Timestamp(randomTime.Unix())
 }
 
-// NIP17DirectMessage creates an encrypted NIP-17 direct message (kind 14)
+// NIP17DirectMessage creates an encrypted NIP-17 direct message (kind 14).
+// recipientPubKeys must be hex public keys.
 func NIP17DirectMessage(content string, recipientPubKeys []string, replyToID string, subject string) *nostr.Event {
 	// Create the unsigned kind 14 event
 	ev := &nostr.Event{
@@ -68,10 +68,10 @@ func NIP17DirectMessage(content string, recipientPubKeys []string, replyToID str
 	return ev
 }
 
-// sealEvent takes an unsigned event (kind 14 or 15) and seals it (kind 13)
-func sealEvent(unsignedEvent *nostr.Event, senderPrivateKey, receiverPubKey string) (*nostr.Event, error) {
+// sealEvent takes an unsigned event (kind 14 or 15) and seals it (kind 13) with the sender's signer
+func sealEvent(ctx context.Context, unsignedEvent *nostr.Event, signer Signer, receiverPubKey string) (*nostr.Event, error) {
 	// Get sender's public key
-	senderPubKey, err := GetPublicKeyFromPrivate(senderPrivateKey)
+	senderPubKey, err := signer.GetPublicKey(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -79,20 +79,14 @@ func sealEvent(unsignedEvent *nostr.Event, senderPrivateKey, receiverPubKey stri
 	// Set the pubkey in the unsigned event to the sender's pubkey
 	unsignedEvent.PubKey = senderPubKey
 
-	// Generate conversation key from sender's private key and receiver's public key
-	conversationKey, err := nip44.GenerateConversationKey(receiverPubKey, senderPrivateKey)
-	if err != nil {
-		return nil, err
-	}
-
 	// Serialize unsigned event to JSON
 	eventJSON, err := json.Marshal(unsignedEvent)
 	if err != nil {
 		return nil, err
 	}
 
-	// Encrypt the unsigned event content with NIP-44
-	encryptedContent, err := nip44.Encrypt(string(eventJSON), conversationKey)
+	// Encrypt the unsigned event for the receiver with NIP-44
+	encryptedContent, err := signer.NIP44Encrypt(ctx, string(eventJSON), receiverPubKey)
 	if err != nil {
 		return nil, err
 	}
@@ -107,7 +101,7 @@ func sealEvent(unsignedEvent *nostr.Event, senderPrivateKey, receiverPubKey stri
 	}
 
 	// Sign the sealed event
-	err = sealedEvent.Sign(senderPrivateKey)
+	err = signer.SignEvent(ctx, sealedEvent)
 	if err != nil {
 		return nil, err
 	}
@@ -115,8 +109,8 @@ func sealEvent(unsignedEvent *nostr.Event, senderPrivateKey, receiverPubKey stri
 	return sealedEvent, nil
 }
 
-// giftWrapEvent takes a sealed event and gift wraps it (kind 1059)
-func giftWrapEvent(sealedEvent *nostr.Event, receiverPubKey string) (*nostr.Event, error) {
+// giftWrapEvent takes a sealed event and gift wraps it (kind 1059), adding expirationTag if it's not nil
+func giftWrapEvent(sealedEvent *nostr.Event, receiverPubKey string, expirationTag nostr.Tag) (*nostr.Event, error) {
 	// Generate random private key for the gift wrap
 	randomPrivateKey, err := generateRandomPrivateKey()
 	if err != nil {
@@ -155,6 +149,9 @@ func giftWrapEvent(sealedEvent *nostr.Event, receiverPubKey string) (*nostr.Even
 		PubKey:    randomPubKey,
 		Tags:      nostr.Tags{nostr.Tag{"p", receiverPubKey}},
 	}
+	if expirationTag != nil {
+		giftWrapEvent.Tags = append(giftWrapEvent.Tags, expirationTag)
+	}
 
 	// Sign the gift wrap with the random private key
 	err = giftWrapEvent.Sign(randomPrivateKey)
@@ -165,42 +162,36 @@ func giftWrapEvent(sealedEvent *nostr.Event, receiverPubKey string) (*nostr.Even
 	return giftWrapEvent, nil
 }
 
-// getPreferredNIP17Relays fetches recipient's preferred DM relays
-func getPreferredNIP17Relays(ctx context.Context, pubKey string, knownRelays []string) ([]string, error) {
-	// Try to find the user's kind 10050 events
-	preferredRelays := []string{}
-
-	// First check known relays
-	for _, relayURL := range knownRelays {
-		relay, err := nostr.RelayConnect(ctx, relayURL)
-		if err != nil {
-			continue // Skip this relay, try the next one
-		}
-		defer relay.Close()
-
-		sub, err := relay.Subscribe(ctx, nostr.Filters{
-			{
-				Kinds:   []int{10050},
-				Authors: []string{pubKey},
-				Limit:   1,
-			},
-		})
-		if err != nil {
-			continue // Skip if subscription fails
+// getPreferredNIP17Relays fetches recipient's preferred DM relays from their newest kind 10050 event.
+// Results, including the absence of a list, are cached for the client's relay list TTL.
+func (c *Client) getPreferredNIP17Relays(ctx context.Context, pubKey string, knownRelays []string) ([]string, error) {
+	if relays, ok := c.dmRelays.get(pubKey); ok {
+		if len(relays) == 0 {
+			return nil, ErrNIP17IncompatibleRelay
 		}
+		return relays, nil
+	}
 
-		for ev := range sub.Events {
-			for _, tag := range ev.Tags {
-				if len(tag) >= 2 && tag[0] == "relay" {
-					preferredRelays = append(preferredRelays, tag[1])
-				}
-			}
-			// If we found relays, we're done
-			if len(preferredRelays) > 0 {
-				return preferredRelays, nil
+	// Ask every known relay at once; each stops at EOSE
+	events, err := c.query(ctx, nostr.Filter{
+		Kinds:   []int{10050},
+		Authors: []string{pubKey},
+		Limit:   1,
+	}, knownRelays)
+	if err != nil {
+		// Not cached, the relays may just be unreachable right now
+		return nil, err
+	}
+
+	preferredRelays := []string{}
+	if newest := newestEvent(events); newest != nil {
+		for _, tag := range newest.Tags {
+			if len(tag) >= 2 && tag[0] == "relay" {
+				preferredRelays = append(preferredRelays, tag[1])
 			}
 		}
 	}
+	c.dmRelays.set(pubKey, preferredRelays)
 
 	// If we didn't find any preferred relays, the user might not be ready for NIP-17
 	if len(preferredRelays) == 0 {
@@ -210,24 +201,47 @@ func getPreferredNIP17Relays(ctx context.Context, pubKey string, knownRelays []s
 	return preferredRelays, nil
 }
 
-// SendNIP17DirectMessage sends a private direct message using NIP-17
-func SendNIP17DirectMessage(ctx context.Context, privateKey string, recipientKeys []string,
-	message string, relayURLs []string, replyToID, subject, clientID string) (string, error) {
+// SendNIP17DirectMessage sends a private direct message using NIP-17.
+// The client's pool is used for relay discovery and for the sender's own copy.
+// A non-zero expiration adds NIP-40 expiration tags to the message and its gift wraps.
+func (c *Client) SendNIP17DirectMessage(ctx context.Context, recipientKeys []string,
+	message, replyToID, subject, clientID string, expiration time.Duration) (*PublishReport, error) {
 
-	// Get sender's public key
-	senderPubKey, err := GetPublicKeyFromPrivate(privateKey)
-	if err != nil {
-		return "", err
+	// p tags must hold hex keys, otherwise recipients compute a different room
+	recipientPubKeys := make([]string, 0, len(recipientKeys))
+	for _, recipientKey := range recipientKeys {
+		recipientPubKey, err := DecodePublicKey(recipientKey)
+		if err != nil {
+			return nil, err
+		}
+		recipientPubKeys = append(recipientPubKeys, recipientPubKey)
 	}
 
 	// Create unsigned kind 14 event
-	unsignedDM := NIP17DirectMessage(message, recipientKeys, replyToID, subject)
+	unsignedDM := NIP17DirectMessage(message, recipientPubKeys, replyToID, subject)
 
 	// Add client tag if provided
 	if clientID != "" {
 		unsignedDM.Tags = append(unsignedDM.Tags, nostr.Tag{"client", clientID})
 	}
 
+	// Add expiration tag if requested, sendNIP17Rumor copies it to the gift wraps
+	if expiration > 0 {
+		unsignedDM.Tags = append(unsignedDM.Tags, expirationTag(expiration))
+	}
+
+	return c.sendNIP17Rumor(ctx, unsignedDM, recipientPubKeys)
+}
+
+// sendNIP17Rumor seals and gift wraps an unsigned kind 14 or 15 event for every recipient and the sender,
+// and publishes each wrap to its recipient's preferred DM relays
+func (c *Client) sendNIP17Rumor(ctx context.Context, unsignedDM *nostr.Event, recipientKeys []string) (*PublishReport, error) {
+	senderPubKey := c.pubKey
+	relayURLs := c.Relays()
+
+	// The gift wraps must expire with the message, or relays would keep serving them
+	expiration := unsignedDM.Tags.Find("expiration")
+
 	// Track the gift-wrapped events we create
 	giftWraps := []*nostr.Event{}
 
@@ -236,33 +250,33 @@ func SendNIP17DirectMessage(ctx context.Context, privateKey string, recipientKey
 		// Decode recipient's key if in NIP-19 format
 		recipientPubKey, err := DecodePublicKey(recipientKey)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		// Create sealed event
-		sealedEvent, err := sealEvent(unsignedDM, privateKey, recipientPubKey)
+		sealedEvent, err := sealEvent(ctx, unsignedDM, c.signer, recipientPubKey)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		// Create gift wrap
-		giftWrap, err := giftWrapEvent(sealedEvent, recipientPubKey)
+		giftWrap, err := giftWrapEvent(sealedEvent, recipientPubKey, expiration)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		giftWraps = append(giftWraps, giftWrap)
 	}
 
 	// Also create a gift wrap for the sender (so they can see their own messages)
-	sealedForSender, err := sealEvent(unsignedDM, privateKey, senderPubKey)
+	sealedForSender, err := sealEvent(ctx, unsignedDM, c.signer, senderPubKey)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	senderGiftWrap, err := giftWrapEvent(sealedForSender, senderPubKey)
+	senderGiftWrap, err := giftWrapEvent(sealedForSender, senderPubKey, expiration)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	giftWraps = append(giftWraps, senderGiftWrap)
 
@@ -272,7 +286,7 @@ func SendNIP17DirectMessage(ctx context.Context, privateKey string, recipientKey
 	for _, recipientKey := range recipientKeys {
 		recipientPubKey, _ := DecodePublicKey(recipientKey)
 		// Try to get preferred relays
-		preferredRelays, err := getPreferredNIP17Relays(ctx, recipientPubKey, relayURLs)
+		preferredRelays, err := c.getPreferredNIP17Relays(ctx, recipientPubKey, relayURLs)
 		if err != nil {
 			// If no preferred relays, fall back to provided relays
 			recipientRelays[recipientPubKey] = relayURLs
@@ -284,11 +298,11 @@ func SendNIP17DirectMessage(ctx context.Context, privateKey string, recipientKey
 	// Also add sender's relays (for their own copy)
 	recipientRelays[senderPubKey] = relayURLs
 
-	// Publish each gift wrap to the appropriate relays
-	var publishErr error
-	var firstNoteID string
+	// Publish each gift wrap to the appropriate relays. Recipients' inbox relays get
+	// transient connections, so they never become targets of our own posts and queries.
+	report := &PublishReport{}
 
-	for i, giftWrap := range giftWraps {
+	for _, giftWrap := range giftWraps {
 		// Get the recipient from the p tag
 		var recipient string
 		for _, tag := range giftWrap.Tags {
@@ -304,51 +318,23 @@ func SendNIP17DirectMessage(ctx context.Context, privateKey string, recipientKey
 			continue // Skip if no relays for this recipient
 		}
 
-		// Publish to each relay for this recipient
-		for _, relayURL := range recipientRelayList {
-			relay, err := nostr.RelayConnect(ctx, relayURL)
-			if err != nil {
-				publishErr = err
-				continue // Try next relay
-			}
-
-			err = relay.Publish(ctx, *giftWrap)
-			relay.Close()
-
-			if err != nil {
-				publishErr = err
-				continue // Try next relay
-			}
-
-			// Store the first successfully published note ID
-			if i == 0 && firstNoteID == "" {
-				noteID, err := nip19.EncodeNote(giftWrap.ID)
-				if err == nil {
-					firstNoteID = noteID
-				}
-			}
-		}
+		eventReport := c.publish(ctx, *giftWrap, recipientRelayList)
+		eventReport.Recipient = recipient
+		report.add(eventReport)
 	}
 
-	// If we never published successfully, return the error
-	if firstNoteID == "" && publishErr != nil {
-		return "", publishErr
+	if expiration != nil {
+		c.addExpirationWarnings(ctx, report)
 	}
 
-	return firstNoteID, nil
+	return report, report.err()
 }
 
-// PublishNIP17Preferences publishes the user's NIP-17 preferred relays
-func PublishNIP17Preferences(ctx context.Context, privateKey string, preferredRelayURLs []string) (string, error) {
-	// Get sender's public key
-	pubKey, err := GetPublicKeyFromPrivate(privateKey)
-	if err != nil {
-		return "", err
-	}
-
+// PublishNIP17Preferences publishes the user's NIP-17 preferred relays to those same relays
+func (c *Client) PublishNIP17Preferences(ctx context.Context, preferredRelayURLs []string) (*PublishReport, error) {
 	// Create the event
 	ev := nostr.Event{
-		PubKey:    pubKey,
+		PubKey:    c.pubKey,
 		CreatedAt: nostr.Timestamp(time.Now().Unix()),
 		Kind:      10050, // NIP-17 preferred relays
 		Tags:      nostr.Tags{},
@@ -361,41 +347,12 @@ func PublishNIP17Preferences(ctx context.Context, privateKey string, preferredRe
 	}
 
 	// Sign the event
-	err = ev.Sign(privateKey)
+	err := c.signer.SignEvent(ctx, &ev)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Publish to all provided relays
-	var publishErr error
-	published := false
-
-	for _, relayURL := range preferredRelayURLs {
-		relay, err := nostr.RelayConnect(ctx, relayURL)
-		if err != nil {
-			publishErr = err
-			continue // Try next relay
-		}
-		defer relay.Close()
-
-		err = relay.Publish(ctx, ev)
-		if err != nil {
-			publishErr = err
-			continue // Try next relay
-		}
-
-		published = true
-	}
-
-	if !published && publishErr != nil {
-		return "", publishErr
-	}
-
-	// Get note ID in bech32 format
-	noteID, err := nip19.EncodeNote(ev.ID)
-	if err != nil {
-		return "", err
-	}
-
-	return noteID, nil
+	report := (&PublishReport{}).add(c.publish(ctx, ev, preferredRelayURLs))
+	return report, report.err()
 }
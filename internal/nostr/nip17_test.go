@@ -0,0 +1,113 @@
+package nostr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestPublishNIP17Preferences(t *testing.T) {
+	inbox1, inbox2 := newTestRelay(t), newTestRelay(t)
+	client := newTestClient(t)
+
+	report, err := client.PublishNIP17Preferences(context.Background(), []string{inbox1.URL, inbox2.URL})
+	if err != nil {
+		t.Fatalf("PublishNIP17Preferences: %v", err)
+	}
+	if report.Events[0].AcceptedCount() != 2 {
+		t.Fatalf("accepted by %d relays, want both", report.Events[0].AcceptedCount())
+	}
+
+	stored := inbox1.Query(nostr.Filter{Kinds: []int{10050}})
+	if len(stored) != 1 || len(inbox2.Query(nostr.Filter{Kinds: []int{10050}})) != 1 {
+		t.Fatal("preferences not stored on every listed relay")
+	}
+	for _, url := range []string{inbox1.URL, inbox2.URL} {
+		if stored[0].Tags.FindWithValue("relay", url) == nil {
+			t.Errorf("preferences don't list %s", url)
+		}
+	}
+}
+
+func TestSendNIP17DirectMessageRoundTrip(t *testing.T) {
+	pool, bobInbox := newTestRelay(t), newTestRelay(t)
+	ctx := context.Background()
+
+	// Bob reads his DMs from a relay Alice doesn't use. The preferences go to that relay,
+	// so copy them to the shared relay where Alice looks them up.
+	bob := newTestClient(t, pool.URL)
+	if _, err := bob.PublishNIP17Preferences(ctx, []string{bobInbox.URL}); err != nil {
+		t.Fatalf("PublishNIP17Preferences: %v", err)
+	}
+	pool.AddEvent(bobInbox.Query(nostr.Filter{Kinds: []int{10050}})[0])
+
+	alice := newTestClient(t, pool.URL)
+	report, err := alice.SendNIP17DirectMessage(ctx, []string{bob.GetPublicKeyBech32()}, "hi bob", "", "plans", "test", 0)
+	if err != nil {
+		t.Fatalf("SendNIP17DirectMessage: %v", err)
+	}
+	if len(report.Events) != 2 {
+		t.Fatalf("got %d gift wraps, want one for Bob and one for Alice", len(report.Events))
+	}
+
+	// Bob's wrap goes to his inbox, Alice's copy to her pool
+	if wraps := bobInbox.Query(nostr.Filter{Kinds: []int{1059}, Tags: nostr.TagMap{"p": {bob.GetPublicKey()}}}); len(wraps) != 1 {
+		t.Fatalf("Bob's inbox has %d gift wraps for him, want 1", len(wraps))
+	}
+	if wraps := pool.Query(nostr.Filter{Kinds: []int{1059}, Tags: nostr.TagMap{"p": {alice.GetPublicKey()}}}); len(wraps) != 1 {
+		t.Fatalf("Alice's relay has %d gift wraps for her, want 1", len(wraps))
+	}
+	if wraps := pool.Query(nostr.Filter{Kinds: []int{1059}, Tags: nostr.TagMap{"p": {bob.GetPublicKey()}}}); len(wraps) != 0 {
+		t.Error("Bob's gift wrap leaked to a relay outside his inbox list")
+	}
+
+	reader := newSignerClient(t, bob.Signer(), bobInbox.URL)
+	messages, err := reader.FetchNIP17Messages(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("FetchNIP17Messages: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(messages))
+	}
+	if msg := messages[0]; msg.Content != "hi bob" || msg.Sender != alice.GetPublicKey() || msg.Subject != "plans" {
+		t.Errorf("message = %+v", msg)
+	}
+}
+
+func TestNIP17RumorIDIsRecomputed(t *testing.T) {
+	relay := newTestRelay(t)
+	alice, bob := newTestClient(t, relay.URL), newTestClient(t, relay.URL)
+	ctx := context.Background()
+
+	// Two different rumors claiming the same ID must both be delivered, under their real IDs
+	forgedID := "0000000000000000000000000000000000000000000000000000000000000000"
+	for _, content := range []string{"first", "second"} {
+		rumor := NIP17DirectMessage(content, []string{bob.GetPublicKey()}, "", "")
+		rumor.ID = forgedID
+
+		seal, err := sealEvent(ctx, rumor, alice.Signer(), bob.GetPublicKey())
+		if err != nil {
+			t.Fatalf("sealEvent: %v", err)
+		}
+		wrap, err := giftWrapEvent(seal, bob.GetPublicKey(), nil)
+		if err != nil {
+			t.Fatalf("giftWrapEvent: %v", err)
+		}
+		relay.AddEvent(wrap)
+	}
+
+	messages, err := bob.FetchNIP17Messages(ctx, time.Time{})
+	if err != nil {
+		t.Fatalf("FetchNIP17Messages: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want both", len(messages))
+	}
+	for _, msg := range messages {
+		if msg.ID == forgedID || msg.ID != msg.Event.GetID() {
+			t.Errorf("message %q has ID %s, want its computed ID", msg.Content, msg.ID)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package nostr
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// FileEncryptionAlgorithm is the only encryption-algorithm NIP-17 defines for kind 15
+const FileEncryptionAlgorithm = "aes-gcm"
+
+// MaxFileDownloadSize caps downloads of attachments, whatever size they declare
+const MaxFileDownloadSize int64 = 100 << 20
+
+var (
+	ErrUnsupportedFileEncryption = errors.New("unsupported file encryption algorithm")
+	ErrFileHashMismatch          = errors.New("downloaded file does not match its hash")
+	ErrFileSizeMismatch          = errors.New("downloaded file does not match its declared size")
+	ErrFileTooLarge              = errors.New("file is larger than the download limit")
+)
+
+// FileAttachment describes an encrypted file referenced by a kind 15 message
+type FileAttachment struct {
+	URL          string
+	Type         string
+	Algorithm    string
+	Key          string
+	Nonce        string
+	Hash         string // SHA-256 of the encrypted file
+	OriginalHash string // SHA-256 of the file before encryption
+	Size         int64  // Size of the encrypted file in bytes
+}
+
+// encryptFile encrypts data with a fresh AES-256-GCM key and returns the ciphertext and its attachment metadata
+func encryptFile(data []byte) ([]byte, *FileAttachment, error) {
+	key := make([]byte, 32)
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(key); err != nil {
+		return nil, nil, err
+	}
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext := gcm.Seal(nil, nonce, data, nil)
+
+	hash := sha256.Sum256(ciphertext)
+	originalHash := sha256.Sum256(data)
+
+	return ciphertext, &FileAttachment{
+		Algorithm:    FileEncryptionAlgorithm,
+		Key:          hex.EncodeToString(key),
+		Nonce:        hex.EncodeToString(nonce),
+		Hash:         hex.EncodeToString(hash[:]),
+		OriginalHash: hex.EncodeToString(originalHash[:]),
+		Size:         int64(len(ciphertext)),
+	}, nil
+}
+
+// Decrypt verifies an encrypted file against the attachment's hash and decrypts it
+func (f *FileAttachment) Decrypt(ciphertext []byte) ([]byte, error) {
+	if f.Algorithm != FileEncryptionAlgorithm {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedFileEncryption, f.Algorithm)
+	}
+
+	if f.Hash != "" {
+		hash := sha256.Sum256(ciphertext)
+		if hex.EncodeToString(hash[:]) != f.Hash {
+			return nil, ErrFileHashMismatch
+		}
+	}
+
+	key, err := hex.DecodeString(f.Key)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	nonce, err := hex.DecodeString(f.Nonce)
+	if err != nil || len(nonce) == 0 {
+		return nil, ErrDecryptionFailed
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+	// Some clients use 16 byte nonces, so accept whatever length was sent
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(nonce))
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrDecryptionFailed
+	}
+
+	if f.OriginalHash != "" {
+		originalHash := sha256.Sum256(plaintext)
+		if hex.EncodeToString(originalHash[:]) != f.OriginalHash {
+			return nil, ErrFileHashMismatch
+		}
+	}
+
+	return plaintext, nil
+}
+
+// Download fetches the encrypted file from its URL and decrypts it. It reads no more than
+// the declared Size, or MaxFileDownloadSize when the sender gave none.
+func (f *FileAttachment) Download(ctx context.Context) ([]byte, error) {
+	if f.Size > MaxFileDownloadSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrFileTooLarge, f.Size)
+	}
+	limit := MaxFileDownloadSize
+	if f.Size > 0 {
+		limit = f.Size
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("downloading %s: server returned %d", f.URL, resp.StatusCode)
+	}
+
+	// Read one byte past the limit to tell a file that fits from one that doesn't
+	ciphertext, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(ciphertext)) > limit {
+		if f.Size > 0 {
+			return nil, ErrFileSizeMismatch
+		}
+		return nil, ErrFileTooLarge
+	}
+	if f.Size > 0 && int64(len(ciphertext)) != f.Size {
+		return nil, ErrFileSizeMismatch
+	}
+
+	return f.Decrypt(ciphertext)
+}
+
+// fileAttachmentFromTags reads the kind 15 metadata tags of a rumor
+func fileAttachmentFromTags(url string, tags nostr.Tags) *FileAttachment {
+	file := &FileAttachment{URL: url}
+
+	for _, tag := range tags {
+		if len(tag) < 2 {
+			continue
+		}
+		switch tag[0] {
+		case "file-type":
+			file.Type = tag[1]
+		case "encryption-algorithm":
+			file.Algorithm = tag[1]
+		case "decryption-key":
+			file.Key = tag[1]
+		case "decryption-nonce":
+			file.Nonce = tag[1]
+		case "x":
+			file.Hash = tag[1]
+		case "ox":
+			file.OriginalHash = tag[1]
+		case "size":
+			file.Size, _ = strconv.ParseInt(tag[1], 10, 64)
+		}
+	}
+
+	return file
+}
+
+// NIP17FileMessage creates an unsigned NIP-17 file message (kind 15) for an uploaded encrypted file
+func NIP17FileMessage(file *FileAttachment, recipientPubKeys []string, replyToID string, subject string) *nostr.Event {
+	// Create the unsigned kind 15 event, its content is the file URL
+	ev := &nostr.Event{
+		Kind:      15, // File message
+		Content:   file.URL,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{},
+	}
+
+	// Add recipient p tags
+	for _, pubKey := range recipientPubKeys {
+		ev.Tags = append(ev.Tags, nostr.Tag{"p", pubKey})
+	}
+
+	// Add reply tag if this is a reply
+	if replyToID != "" {
+		ev.Tags = append(ev.Tags, nostr.Tag{"e", replyToID})
+	}
+
+	// Add subject if provided
+	if subject != "" {
+		ev.Tags = append(ev.Tags, nostr.Tag{"subject", subject})
+	}
+
+	// Add what the receiver needs to fetch, verify and decrypt the file
+	ev.Tags = append(ev.Tags,
+		nostr.Tag{"file-type", file.Type},
+		nostr.Tag{"encryption-algorithm", file.Algorithm},
+		nostr.Tag{"decryption-key", file.Key},
+		nostr.Tag{"decryption-nonce", file.Nonce},
+		nostr.Tag{"x", file.Hash},
+		nostr.Tag{"ox", file.OriginalHash},
+		nostr.Tag{"size", strconv.FormatInt(file.Size, 10)},
+	)
+
+	return ev
+}
+
+// SendNIP17File encrypts a local file, uploads the ciphertext and sends it to recipients as a kind 15 message.
+// A non-zero expiration adds NIP-40 expiration tags to the message and its gift wraps.
+func (c *Client) SendNIP17File(ctx context.Context, recipientKeys []string, filePath string, uploader FileUploader,
+	replyToID, subject, clientID string, expiration time.Duration) (*PublishReport, error) {
+
+	// Decode recipients' keys before uploading anything
+	recipientPubKeys := make([]string, 0, len(recipientKeys))
+	for _, recipientKey := range recipientKeys {
+		recipientPubKey, err := DecodePublicKey(recipientKey)
+		if err != nil {
+			return nil, err
+		}
+		recipientPubKeys = append(recipientPubKeys, recipientPubKey)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Encrypt the file and upload only the ciphertext
+	ciphertext, file, err := encryptFile(data)
+	if err != nil {
+		return nil, ErrEncryptionFailed
+	}
+	file.Type = detectFileType(filePath, data)
+
+	file.URL, err = uploader.Upload(ctx, ciphertext, "application/octet-stream")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create unsigned kind 15 event
+	unsignedFile := NIP17FileMessage(file, recipientPubKeys, replyToID, subject)
+
+	// Add client tag if provided
+	if clientID != "" {
+		unsignedFile.Tags = append(unsignedFile.Tags, nostr.Tag{"client", clientID})
+	}
+
+	// Add expiration tag if requested, sendNIP17Rumor copies it to the gift wraps
+	if expiration > 0 {
+		unsignedFile.Tags = append(unsignedFile.Tags, expirationTag(expiration))
+	}
+
+	return c.sendNIP17Rumor(ctx, unsignedFile, recipientPubKeys)
+}
+
+// detectFileType guesses a MIME type from the file extension, falling back to sniffing the content
+func detectFileType(filePath string, data []byte) string {
+	fileType := mime.TypeByExtension(filepath.Ext(filePath))
+	if fileType == "" {
+		fileType = http.DetectContentType(data)
+	}
+
+	// file-type is a bare MIME type, drop parameters such as charset
+	if mediaType, _, err := mime.ParseMediaType(fileType); err == nil {
+		return mediaType
+	}
+	return fileType
+}
@@ -0,0 +1,246 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// maxOutboxRelaysPerUser limits how many of a mentioned user's read relays a post is sent to
+const maxOutboxRelaysPerUser = 4
+
+var (
+	ErrRelayListNotFound = errors.New("no NIP-65 relay list found")
+)
+
+// RelayListEntry is one relay of a NIP-65 relay list
+type RelayListEntry struct {
+	URL   string `json:"url"`
+	Read  bool   `json:"read"`
+	Write bool   `json:"write"`
+}
+
+// RelayList is a user's NIP-65 (kind 10002) relay list metadata.
+// Others read the user's posts from its write relays and send mentions to its read relays.
+type RelayList struct {
+	PubKey    string           `json:"pubkey"`
+	Entries   []RelayListEntry `json:"relays"`
+	CreatedAt nostr.Timestamp  `json:"created_at"`
+}
+
+// ReadRelays returns the relays the user reads mentions from
+func (l *RelayList) ReadRelays() []string {
+	relays := []string{}
+	for _, entry := range l.Entries {
+		if entry.Read {
+			relays = append(relays, entry.URL)
+		}
+	}
+	return relays
+}
+
+// WriteRelays returns the relays the user publishes to
+func (l *RelayList) WriteRelays() []string {
+	relays := []string{}
+	for _, entry := range l.Entries {
+		if entry.Write {
+			relays = append(relays, entry.URL)
+		}
+	}
+	return relays
+}
+
+// relayListFromEvent parses the r tags of a kind 10002 event
+func relayListFromEvent(ev *nostr.Event) *RelayList {
+	list := &RelayList{
+		PubKey:    ev.PubKey,
+		Entries:   []RelayListEntry{},
+		CreatedAt: ev.CreatedAt,
+	}
+
+	for _, tag := range ev.Tags {
+		if len(tag) < 2 || tag[0] != "r" {
+			continue
+		}
+
+		// A relay without a marker is used for both reading and writing
+		entry := RelayListEntry{URL: nostr.NormalizeURL(tag[1]), Read: true, Write: true}
+		if len(tag) >= 3 {
+			switch tag[2] {
+			case "read":
+				entry.Write = false
+			case "write":
+				entry.Read = false
+			}
+		}
+		list.Entries = append(list.Entries, entry)
+	}
+
+	return list
+}
+
+// PublishRelayList replaces our NIP-65 relay list. It is published to the pool and to every listed relay
+// so others can find it.
+func (c *Client) PublishRelayList(ctx context.Context, entries []RelayListEntry) (*PublishReport, error) {
+	// Create the event
+	ev := nostr.Event{
+		PubKey:    c.pubKey,
+		CreatedAt: nostr.Now(),
+		Kind:      nostr.KindRelayListMetadata,
+		Tags:      nostr.Tags{},
+		Content:   "",
+	}
+
+	// Add relay tags, marking relays used for only one direction
+	targets := c.Relays()
+	for _, entry := range entries {
+		if !entry.Read && !entry.Write {
+			continue
+		}
+
+		tag := nostr.Tag{"r", nostr.NormalizeURL(entry.URL)}
+		switch {
+		case entry.Read && !entry.Write:
+			tag = append(tag, "read")
+		case entry.Write && !entry.Read:
+			tag = append(tag, "write")
+		}
+		ev.Tags = append(ev.Tags, tag)
+		targets = append(targets, nostr.NormalizeURL(entry.URL))
+	}
+
+	// Sign the event
+	err := c.signer.SignEvent(ctx, &ev)
+	if err != nil {
+		return nil, err
+	}
+
+	// Our cached view of our own relays is now stale
+	c.readRelays.delete(c.pubKey)
+	c.writeRelays.delete(c.pubKey)
+
+	report := (&PublishReport{}).add(c.publish(ctx, ev, uniqueStrings(targets)))
+	return report, report.err()
+}
+
+// FetchRelayList fetches the newest NIP-65 relay list of a public key (hex, npub, or nprofile) from the pool
+func (c *Client) FetchRelayList(ctx context.Context, pubKey string) (*RelayList, error) {
+	pubKeyHex, err := DecodePublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ev, err := c.fetchLatest(ctx, nostr.KindRelayListMetadata, pubKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	if ev == nil {
+		return nil, ErrRelayListNotFound
+	}
+
+	list := relayListFromEvent(ev)
+	c.readRelays.set(pubKeyHex, list.ReadRelays())
+	c.writeRelays.set(pubKeyHex, list.WriteRelays())
+	return list, nil
+}
+
+// SetOutboxRouting turns outbox-model routing of public posts on or off. It is on by default.
+func (c *Client) SetOutboxRouting(enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outbox = enabled
+}
+
+// publishRouted publishes an event to the pool and, with outbox routing on, to our NIP-65 write relays
+// and the read relays of every user mentioned in a p tag
+func (c *Client) publishRouted(ctx context.Context, event *nostr.Event) (*PublishReport, error) {
+	relays := c.Relays()
+	if len(relays) == 0 {
+		return nil, ErrNoRelayConnected
+	}
+
+	c.mu.Lock()
+	outbox := c.outbox
+	c.mu.Unlock()
+
+	if outbox {
+		mentioned := []string{}
+		for _, tag := range event.Tags {
+			if len(tag) >= 2 && tag[0] == "p" && tag[1] != c.pubKey && nostr.IsValidPublicKey(tag[1]) {
+				mentioned = append(mentioned, tag[1])
+			}
+		}
+
+		// Relay lists are best effort, the pool alone is still a valid target
+		c.loadRelayLists(ctx, append(mentioned, c.pubKey))
+
+		own, _ := c.writeRelays.get(c.pubKey)
+		relays = append(relays, own...)
+		for _, pubKey := range mentioned {
+			inbox, _ := c.readRelays.get(pubKey)
+			if len(inbox) > maxOutboxRelaysPerUser {
+				inbox = inbox[:maxOutboxRelaysPerUser]
+			}
+			relays = append(relays, inbox...)
+		}
+	}
+
+	// Outbox targets outside the pool get transient connections and don't join it
+	report := (&PublishReport{}).add(c.publish(ctx, *event, uniqueStrings(relays)))
+	return report, report.err()
+}
+
+// loadRelayLists fetches the relay lists of every pubKey that isn't cached, in a single query
+func (c *Client) loadRelayLists(ctx context.Context, pubKeys []string) {
+	missing := []string{}
+	for _, pubKey := range uniqueStrings(pubKeys) {
+		if _, ok := c.readRelays.get(pubKey); !ok {
+			missing = append(missing, pubKey)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	events, err := c.query(ctx, nostr.Filter{
+		Kinds:   []int{nostr.KindRelayListMetadata},
+		Authors: missing,
+	}, c.Relays())
+	if err != nil {
+		return
+	}
+
+	// Keep the newest list per author
+	newest := make(map[string]*nostr.Event)
+	for _, ev := range events {
+		if current, ok := newest[ev.PubKey]; !ok || ev.CreatedAt > current.CreatedAt {
+			newest[ev.PubKey] = ev
+		}
+	}
+
+	for _, pubKey := range missing {
+		// Users without a list are cached too, so we don't ask again on every post
+		read, write := []string{}, []string{}
+		if ev, ok := newest[pubKey]; ok {
+			list := relayListFromEvent(ev)
+			read, write = list.ReadRelays(), list.WriteRelays()
+		}
+		c.readRelays.set(pubKey, read)
+		c.writeRelays.set(pubKey, write)
+	}
+}
+
+// uniqueStrings removes duplicates while keeping the original order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool)
+	unique := []string{}
+	for _, value := range values {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		unique = append(unique, value)
+	}
+	return unique
+}
@@ -0,0 +1,98 @@
+package nostr
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/konstantinmds/nostr_demo_golang/internal/relaytest"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestRelayListRoundTrip(t *testing.T) {
+	pool, both, read, write := newTestRelay(t), newTestRelay(t), newTestRelay(t), newTestRelay(t)
+	ctx := context.Background()
+	client := newTestClient(t, pool.URL)
+
+	entries := []RelayListEntry{
+		{URL: both.URL, Read: true, Write: true},
+		{URL: read.URL, Read: true},
+		{URL: write.URL, Write: true},
+	}
+	if _, err := client.PublishRelayList(ctx, entries); err != nil {
+		t.Fatalf("PublishRelayList: %v", err)
+	}
+
+	// Only single-direction relays are marked, and every listed relay gets a copy
+	stored := pool.Query(nostr.Filter{Kinds: []int{nostr.KindRelayListMetadata}})
+	if len(stored) != 1 {
+		t.Fatalf("pool has %d relay lists, want 1", len(stored))
+	}
+	want := nostr.Tags{
+		{"r", nostr.NormalizeURL(both.URL)},
+		{"r", nostr.NormalizeURL(read.URL), "read"},
+		{"r", nostr.NormalizeURL(write.URL), "write"},
+	}
+	if !slices.EqualFunc(stored[0].Tags, want, slices.Equal) {
+		t.Errorf("tags = %q, want %q", stored[0].Tags, want)
+	}
+	for _, relay := range []*relaytest.Relay{both, read, write} {
+		if n := kindCount(relay, nostr.KindRelayListMetadata); n != 1 {
+			t.Errorf("%s has %d relay lists, want 1", relay.URL, n)
+		}
+	}
+
+	list, err := newTestClient(t, pool.URL).FetchRelayList(ctx, client.GetPublicKeyBech32())
+	if err != nil {
+		t.Fatalf("FetchRelayList: %v", err)
+	}
+	wantRead := []string{nostr.NormalizeURL(both.URL), nostr.NormalizeURL(read.URL)}
+	wantWrite := []string{nostr.NormalizeURL(both.URL), nostr.NormalizeURL(write.URL)}
+	if !slices.Equal(list.ReadRelays(), wantRead) || !slices.Equal(list.WriteRelays(), wantWrite) {
+		t.Errorf("read %v write %v, want read %v write %v", list.ReadRelays(), list.WriteRelays(), wantRead, wantWrite)
+	}
+}
+
+func TestOutboxRouting(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		// want is how many notes each outbox relay ends up with
+		want int
+	}{
+		{"enabled", true, 1},
+		{"disabled", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, aliceWrite, bobRead := newTestRelay(t), newTestRelay(t), newTestRelay(t)
+			ctx := context.Background()
+
+			bob := newTestClient(t, pool.URL)
+			if _, err := bob.PublishRelayList(ctx, []RelayListEntry{{URL: bobRead.URL, Read: true}}); err != nil {
+				t.Fatalf("PublishRelayList: %v", err)
+			}
+			alice := newTestClient(t, pool.URL)
+			setup := newSignerClient(t, alice.Signer(), pool.URL)
+			if _, err := setup.PublishRelayList(ctx, []RelayListEntry{{URL: aliceWrite.URL, Write: true}}); err != nil {
+				t.Fatalf("PublishRelayList: %v", err)
+			}
+			alice.SetOutboxRouting(tt.enabled)
+
+			// A mention goes to our write relays and the mentioned user's read relays
+			if _, err := alice.SendPublicPost(ctx, "hi bob", "test", nostr.Tags{{"p", bob.GetPublicKey()}}, 0); err != nil {
+				t.Fatalf("SendPublicPost: %v", err)
+			}
+			if n := kindCount(pool, nostr.KindTextNote); n != 1 {
+				t.Errorf("pool has %d notes, want 1", n)
+			}
+			if n := kindCount(aliceWrite, nostr.KindTextNote); n != tt.want {
+				t.Errorf("Alice's write relay has %d notes, want %d", n, tt.want)
+			}
+			if n := kindCount(bobRead, nostr.KindTextNote); n != tt.want {
+				t.Errorf("Bob's read relay has %d notes, want %d", n, tt.want)
+			}
+		})
+	}
+}
@@ -2,24 +2,19 @@ package nostr
 
 import (
 	"context"
-	"strings"
 	"time"
 
 	"github.com/nbd-wtf/go-nostr"
-	"github.com/nbd-wtf/go-nostr/nip19"
 )
 
-// SendPublicPost sends a public post to a relay
-func SendPublicPost(ctx context.Context, privateKey, message, relayURL, clientID, tags string) (string, error) {
-	// Get public key from private key
-	pubKey, err := GetPublicKeyFromPrivate(privateKey)
-	if err != nil {
-		return "", err
-	}
-
+// SendPublicPost sends a public post to every relay in the client's pool and, unless outbox routing
+// is off, to our NIP-65 write relays and the read relays of users mentioned in p tags.
+// A non-zero expiration adds a NIP-40 expiration tag.
+// tags are added as given; build them with ParseTag or ParseTags.
+func (c *Client) SendPublicPost(ctx context.Context, message, clientID string, tags nostr.Tags, expiration time.Duration) (*PublishReport, error) {
 	// Create the event
 	ev := nostr.Event{
-		PubKey:    pubKey,
+		PubKey:    c.pubKey,
 		CreatedAt: nostr.Timestamp(time.Now().Unix()),
 		Kind:      nostr.KindTextNote,
 		Tags:      nostr.Tags{},
@@ -29,55 +24,24 @@ func SendPublicPost(ctx context.Context, privateKey, message, relayURL, clientID
 	// Add client tag
 	ev.Tags = append(ev.Tags, nostr.Tag{"client", clientID})
 
-	// Process custom tags
-	if tags != "" {
-		// Split by commas to get key:value pairs
-		pairs := strings.Split(tags, ",")
-		for _, pair := range pairs {
-			// Split each pair by colon
-			kv := strings.Split(pair, ":")
-			if len(kv) == 2 {
-				key := strings.TrimSpace(kv[0])
-				value := strings.TrimSpace(kv[1])
-				
-				// Handle NIP-19 format keys
-				if key == "p" && (strings.HasPrefix(value, "npub") || strings.HasPrefix(value, "nprofile")) {
-					decodedKey, err := DecodePublicKey(value)
-					if err != nil {
-						return "", err
-					}
-					value = decodedKey
-				}
-				
-				ev.Tags = append(ev.Tags, nostr.Tag{key, value})
-			}
-		}
-	}
-
-	// Sign the event
-	err = ev.Sign(privateKey)
-	if err != nil {
-		return "", err
-	}
+	// Add custom tags
+	ev.Tags = append(ev.Tags, tags...)
 
-	// Connect to relay
-	relay, err := nostr.RelayConnect(ctx, relayURL)
-	if err != nil {
-		return "", err
+	// Add expiration tag if requested
+	if expiration > 0 {
+		ev.Tags = append(ev.Tags, expirationTag(expiration))
 	}
-	defer relay.Close()
 
-	// Publish the event
-	err = relay.Publish(ctx, ev)
+	// Sign the event
+	err := c.signer.SignEvent(ctx, &ev)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Get note ID in bech32 format
-	noteID, err := nip19.EncodeNote(ev.ID)
-	if err != nil {
-		return "", err
+	// Publish the event to our relays and the inboxes of mentioned users
+	report, err := c.publishRouted(ctx, &ev)
+	if expiration > 0 {
+		c.addExpirationWarnings(ctx, report)
 	}
-
-	return noteID, nil
+	return report, err
 } 
\ No newline at end of file
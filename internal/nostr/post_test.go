@@ -0,0 +1,70 @@
+package nostr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestSendPublicPost(t *testing.T) {
+	relay := newTestRelay(t)
+	client := newTestClient(t, relay.URL)
+
+	tags := nostr.Tags{{"t", "nostr"}, {"r", "https://example.com:8080/page"}}
+	report, err := client.SendPublicPost(context.Background(), "hello world", "test-client", tags, time.Hour)
+	if err != nil {
+		t.Fatalf("SendPublicPost: %v", err)
+	}
+	if len(report.Events) != 1 || report.Events[0].AcceptedCount() != 1 {
+		t.Fatalf("report = %+v, want one event accepted by one relay", report.Events)
+	}
+
+	stored := relay.Query(nostr.Filter{IDs: []string{report.Events[0].EventID}})
+	if len(stored) != 1 {
+		t.Fatal("relay doesn't have the post")
+	}
+	post := stored[0]
+	if post.Kind != nostr.KindTextNote || post.Content != "hello world" || post.PubKey != client.GetPublicKey() {
+		t.Errorf("stored post = %+v", post)
+	}
+	for _, want := range []nostr.Tag{{"client", "test-client"}, tags[0], tags[1]} {
+		if post.Tags.FindWithValue(want[0], want[1]) == nil {
+			t.Errorf("post is missing tag %v", want)
+		}
+	}
+	if post.Tags.Find("expiration") == nil {
+		t.Error("post has no expiration tag")
+	}
+}
+
+func TestSendPublicPostReportsRejections(t *testing.T) {
+	accepting, rejecting := newTestRelay(t), newTestRelay(t)
+	rejecting.Reject = func(*nostr.Event) string { return "blocked: no posts" }
+	client := newTestClient(t, accepting.URL, rejecting.URL)
+
+	report, err := client.SendPublicPost(context.Background(), "hello", "test", nil, 0)
+	if err != nil {
+		t.Fatalf("SendPublicPost: %v", err)
+	}
+
+	for _, result := range report.Events[0].Results {
+		switch result.Relay {
+		case nostr.NormalizeURL(accepting.URL):
+			if !result.Accepted {
+				t.Errorf("accepting relay result = %+v", result)
+			}
+		case nostr.NormalizeURL(rejecting.URL):
+			if result.Accepted || result.Message != "blocked: no posts" {
+				t.Errorf("rejecting relay result = %+v", result)
+			}
+		}
+	}
+
+	// Every relay rejecting is an error
+	accepting.Reject = rejecting.Reject
+	if _, err := client.SendPublicPost(context.Background(), "again", "test", nil, 0); err == nil {
+		t.Error("no error when every relay rejected the post")
+	}
+}
@@ -0,0 +1,124 @@
+package nostr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrMetadataNotFound = errors.New("no profile metadata found")
+)
+
+// ProfileMetadata is the JSON content of a kind 0 metadata event
+type ProfileMetadata struct {
+	Name        string `json:"name,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	About       string `json:"about,omitempty"`
+	Picture     string `json:"picture,omitempty"`
+	Banner      string `json:"banner,omitempty"`
+	NIP05       string `json:"nip05,omitempty"`
+	LUD16       string `json:"lud16,omitempty"`
+	Website     string `json:"website,omitempty"`
+
+	// PubKey and CreatedAt describe the event the metadata came from
+	PubKey    string          `json:"-"`
+	CreatedAt nostr.Timestamp `json:"-"`
+}
+
+// fields maps the metadata's JSON keys to their values
+func (p *ProfileMetadata) fields() map[string]string {
+	return map[string]string{
+		"name":         p.Name,
+		"display_name": p.DisplayName,
+		"about":        p.About,
+		"picture":      p.Picture,
+		"banner":       p.Banner,
+		"nip05":        p.NIP05,
+		"lud16":        p.LUD16,
+		"website":      p.Website,
+	}
+}
+
+// fetchMetadataEvent returns the newest kind 0 event of a public key found on the pool
+func (c *Client) fetchMetadataEvent(ctx context.Context, pubKey string) (*nostr.Event, error) {
+	event, err := c.fetchLatest(ctx, nostr.KindProfileMetadata, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, ErrMetadataNotFound
+	}
+	return event, nil
+}
+
+// GetProfile fetches the newest profile metadata of a public key (hex, npub, or nprofile)
+func (c *Client) GetProfile(ctx context.Context, pubKey string) (*ProfileMetadata, error) {
+	pubKeyHex, err := DecodePublicKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := c.fetchMetadataEvent(ctx, pubKeyHex)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &ProfileMetadata{}
+	if err := json.Unmarshal([]byte(event.Content), profile); err != nil {
+		return nil, err
+	}
+	profile.PubKey = event.PubKey
+	profile.CreatedAt = event.CreatedAt
+
+	return profile, nil
+}
+
+// SetProfile publishes our profile metadata. Only the non-empty fields of update are changed;
+// every other field of the existing profile, including ones we don't know about, is kept.
+// It fails with ErrIncompleteRead, without publishing, if any relay in the pool can't be read.
+func (c *Client) SetProfile(ctx context.Context, update *ProfileMetadata) (*PublishReport, error) {
+	content := map[string]any{}
+
+	// Start from the current profile so we don't clobber it, reading strictly (see queryStrict)
+	existing, err := c.fetchLatestStrict(ctx, nostr.KindProfileMetadata, c.pubKey)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if err := json.Unmarshal([]byte(existing.Content), &content); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, value := range update.fields() {
+		if value != "" {
+			content[key] = value
+		}
+	}
+
+	contentJSON, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the event
+	ev := nostr.Event{
+		PubKey:    c.pubKey,
+		CreatedAt: replacementTimestamp(existing),
+		Kind:      nostr.KindProfileMetadata,
+		Tags:      nostr.Tags{},
+		Content:   string(contentJSON),
+	}
+
+	// Sign the event
+	err = c.signer.SignEvent(ctx, &ev)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish the event
+	return c.PublishEvent(ctx, &ev)
+}
@@ -0,0 +1,97 @@
+package nostr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestSetProfileMergesFields(t *testing.T) {
+	relay := newTestRelay(t)
+	client := newTestClient(t, relay.URL)
+	ctx := context.Background()
+
+	// A field this client doesn't know about must survive updates
+	existing := nostr.Event{Kind: nostr.KindProfileMetadata, CreatedAt: nostr.Now() - 10,
+		Content: `{"name":"old","about":"hi","pronouns":"they/them"}`}
+	if err := client.Signer().SignEvent(ctx, &existing); err != nil {
+		t.Fatalf("SignEvent: %v", err)
+	}
+	relay.AddEvent(&existing)
+
+	if _, err := client.SetProfile(ctx, &ProfileMetadata{Name: "new"}); err != nil {
+		t.Fatalf("SetProfile: %v", err)
+	}
+
+	stored := relay.Query(nostr.Filter{Kinds: []int{nostr.KindProfileMetadata}})
+	if len(stored) != 1 {
+		t.Fatalf("relay has %d metadata events, want 1", len(stored))
+	}
+	content := map[string]string{}
+	json.Unmarshal([]byte(stored[0].Content), &content)
+	want := map[string]string{"name": "new", "about": "hi", "pronouns": "they/them"}
+	for key, value := range want {
+		if content[key] != value {
+			t.Errorf("%s = %q, want %q", key, content[key], value)
+		}
+	}
+}
+
+func TestEditsAbortWhenARelayCantBeRead(t *testing.T) {
+	tests := []struct {
+		name string
+		kind int
+		// edit replaces our event of kind, seeding it the first time
+		edit func(ctx context.Context, client *Client, first bool) error
+	}{
+		{
+			name: "profile",
+			kind: nostr.KindProfileMetadata,
+			edit: func(ctx context.Context, client *Client, first bool) error {
+				update := &ProfileMetadata{Website: "https://example.com"}
+				if first {
+					update = &ProfileMetadata{Name: "alice", About: "keep me"}
+				}
+				_, err := client.SetProfile(ctx, update)
+				return err
+			},
+		},
+		{
+			name: "contact list",
+			kind: nostr.KindFollowList,
+			edit: func(ctx context.Context, client *Client, first bool) error {
+				_, err := client.Follow(ctx, []Follow{{PubKey: newTestClient(t).GetPublicKey()}})
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holder, down := newTestRelay(t), newTestRelay(t)
+			ctx := context.Background()
+
+			// The event only lives on one relay
+			setup := newTestClient(t, holder.URL)
+			if err := tt.edit(ctx, setup, true); err != nil {
+				t.Fatalf("seeding: %v", err)
+			}
+			before := holder.Query(nostr.Filter{Kinds: []int{tt.kind}})
+
+			// and the other relay is unreachable when we edit it
+			down.Close()
+			client := newSignerClient(t, setup.Signer(), holder.URL, down.URL)
+			if err := tt.edit(ctx, client, false); !errors.Is(err, ErrIncompleteRead) {
+				t.Fatalf("edit error = %v, want ErrIncompleteRead", err)
+			}
+
+			after := holder.Query(nostr.Filter{Kinds: []int{tt.kind}})
+			if len(after) != 1 || after[0].ID != before[0].ID {
+				t.Errorf("kind %d event changed after a failed read: %v", tt.kind, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strconv"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrMissingEmojiURL = errors.New("custom emoji reaction needs the emoji's image URL")
+)
+
+// shortcodeReaction matches a NIP-30 custom emoji reaction such as :soapbox:
+var shortcodeReaction = regexp.MustCompile(`^:([a-zA-Z0-9_]+):$`)
+
+// CustomEmoji is a NIP-30 custom emoji, referenced in content as :shortcode:
+type CustomEmoji struct {
+	Shortcode string
+	URL       string
+}
+
+// React publishes a kind 7 reaction to an event referenced by hex ID, note1 or nevent1.
+// content is "+" (like, also used when empty), "-" (dislike), an emoji, or a :shortcode: whose
+// image is given in emojis.
+func (c *Client) React(ctx context.Context, eventRef, content string, emojis ...CustomEmoji) (*PublishReport, error) {
+	if content == "" {
+		content = "+"
+	}
+
+	// A custom emoji reaction must carry the image of its shortcode
+	var emojiTag nostr.Tag
+	if match := shortcodeReaction.FindStringSubmatch(content); match != nil {
+		for _, emoji := range emojis {
+			if emoji.Shortcode == match[1] {
+				emojiTag = nostr.Tag{"emoji", emoji.Shortcode, emoji.URL}
+				break
+			}
+		}
+		if emojiTag == nil || emojiTag[2] == "" {
+			return nil, ErrMissingEmojiURL
+		}
+	}
+
+	// The reaction must name the target's author and kind, so fetch it
+	target, err := c.FetchEvent(ctx, eventRef)
+	if err != nil {
+		return nil, err
+	}
+	relayHint := c.eventRelayHint(eventRef)
+
+	// Create the event
+	ev := nostr.Event{
+		PubKey:    c.pubKey,
+		CreatedAt: nostr.Now(),
+		Kind:      nostr.KindReaction,
+		Tags: nostr.Tags{
+			{"e", target.ID, relayHint, target.PubKey},
+			{"p", target.PubKey, relayHint},
+		},
+		Content: content,
+	}
+
+	// Reactions to addressable events also point at the address
+	if address := addressTag(target); address != "" {
+		ev.Tags = append(ev.Tags, nostr.Tag{"a", address, relayHint})
+	}
+
+	ev.Tags = append(ev.Tags, nostr.Tag{"k", strconv.Itoa(target.Kind)})
+	if emojiTag != nil {
+		ev.Tags = append(ev.Tags, emojiTag)
+	}
+
+	// Sign the event
+	err = c.signer.SignEvent(ctx, &ev)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish the event to our relays and the author's inbox relays
+	return c.publishRouted(ctx, &ev)
+}
@@ -0,0 +1,113 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestReact(t *testing.T) {
+	relay := newTestRelay(t)
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+	hint := nostr.NormalizeURL(relay.URL)
+	note := postNote(t, alice, "react to me")
+
+	tests := []struct {
+		name    string
+		content string
+		emojis  []CustomEmoji
+		want    string
+		extra   nostr.Tags
+	}{
+		{name: "like by default", content: "", want: "+"},
+		{name: "dislike", content: "-", want: "-"},
+		{name: "emoji", content: "🤙", want: "🤙"},
+		{
+			name:    "custom emoji",
+			content: ":soapbox:",
+			emojis:  []CustomEmoji{{Shortcode: "other", URL: "https://example.com/other.png"}, {Shortcode: "soapbox", URL: "https://example.com/soapbox.png"}},
+			want:    ":soapbox:",
+			extra:   nostr.Tags{{"emoji", "soapbox", "https://example.com/soapbox.png"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := bob.React(context.Background(), note.ID, tt.content, tt.emojis...)
+			if err != nil {
+				t.Fatalf("React: %v", err)
+			}
+			reaction := fetchByID(t, bob, report.Events[0].EventID)
+
+			if reaction.Kind != nostr.KindReaction || reaction.Content != tt.want {
+				t.Errorf("reaction = kind %d %q, want kind 7 %q", reaction.Kind, reaction.Content, tt.want)
+			}
+			want := append(nostr.Tags{
+				{"e", note.ID, hint, alice.GetPublicKey()},
+				{"p", alice.GetPublicKey(), hint},
+				{"k", "1"},
+			}, tt.extra...)
+			if !slices.EqualFunc(reaction.Tags, want, slices.Equal) {
+				t.Errorf("tags = %q, want %q", reaction.Tags, want)
+			}
+		})
+	}
+}
+
+func TestReactToArticle(t *testing.T) {
+	relay := newTestRelay(t)
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+
+	report, _, err := alice.PublishArticle(context.Background(), &Article{Identifier: "hello", Title: "Hello", Content: "Body"}, false, "")
+	if err != nil {
+		t.Fatalf("PublishArticle: %v", err)
+	}
+
+	report, err = bob.React(context.Background(), report.Events[0].EventID, "+")
+	if err != nil {
+		t.Fatalf("React: %v", err)
+	}
+	reaction := fetchByID(t, bob, report.Events[0].EventID)
+
+	// An addressable target is referenced by its address too, and k names its kind
+	address := "30023:" + alice.GetPublicKey() + ":hello"
+	if reaction.Tags.FindWithValue("a", address) == nil {
+		t.Errorf("tags = %q, want an a tag for %s", reaction.Tags, address)
+	}
+	if k := reaction.Tags.Find("k"); k == nil || k[1] != "30023" {
+		t.Errorf("k tag = %q, want 30023", k)
+	}
+}
+
+func TestReactMissingEmojiURL(t *testing.T) {
+	relay := newTestRelay(t)
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+	note := postNote(t, alice, "react to me")
+
+	tests := []struct {
+		name   string
+		emojis []CustomEmoji
+	}{
+		{"no emojis", nil},
+		{"other shortcode", []CustomEmoji{{Shortcode: "other", URL: "https://example.com/other.png"}}},
+		{"empty URL", []CustomEmoji{{Shortcode: "soapbox"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := bob.React(context.Background(), note.ID, ":soapbox:", tt.emojis...); !errors.Is(err, ErrMissingEmojiURL) {
+				t.Errorf("React error = %v, want ErrMissingEmojiURL", err)
+			}
+		})
+	}
+
+	if count := kindCount(relay, nostr.KindReaction); count != 0 {
+		t.Errorf("relay has %d reactions, want none", count)
+	}
+}
@@ -0,0 +1,214 @@
+package nostr
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// ConnState is the health of a pooled relay connection
+type ConnState int
+
+const (
+	// Disconnected means the relay is in the pool but hasn't been dialed yet
+	Disconnected ConnState = iota
+	Connected
+	Reconnecting
+	Failed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case Disconnected:
+		return "disconnected"
+	case Connected:
+		return "connected"
+	case Reconnecting:
+		return "reconnecting"
+	case Failed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// StateChange is delivered to the state callback whenever a relay connection changes health
+type StateChange struct {
+	Relay   string
+	State   ConnState
+	Attempt int
+	Err     error
+}
+
+// ReconnectPolicy controls how dropped relay connections are re-established
+type ReconnectPolicy struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter randomizes each delay by up to this fraction of it, e.g. 0.2 for ±20%
+	Jitter float64
+	// MaxAttempts is the number of reconnect attempts before giving up, 0 means retry forever
+	MaxAttempts int
+}
+
+// DefaultReconnectPolicy returns the policy used by new clients
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Multiplier:     2,
+		Jitter:         0.2,
+		MaxAttempts:    0,
+	}
+}
+
+// backoff returns the delay before the given reconnect attempt (starting at 1)
+func (p ReconnectPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+// SetReconnectPolicy changes the reconnect policy for connections dropped from now on
+func (c *Client) SetReconnectPolicy(policy ReconnectPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policy = policy
+}
+
+// OnStateChange registers a callback invoked on every connection health change.
+// The callback runs on the client's goroutines and must not block.
+func (c *Client) OnStateChange(fn func(StateChange)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onState = fn
+}
+
+// State returns the health of a relay connection in the pool
+func (c *Client) State(url string) ConnState {
+	c.mu.Lock()
+	conn, ok := c.relays[nostr.NormalizeURL(url)]
+	c.mu.Unlock()
+
+	if !ok {
+		return Disconnected
+	}
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	return conn.state
+}
+
+// notify records a relay's new state and passes it to the registered callback.
+// Transient connections are outside the pool, so the callback doesn't hear about them.
+func (c *Client) notify(rc *relayConn, change StateChange) {
+	rc.mu.Lock()
+	rc.state = change.State
+	transient := rc.transient
+	rc.mu.Unlock()
+
+	if transient {
+		return
+	}
+
+	c.mu.Lock()
+	fn := c.onState
+	c.mu.Unlock()
+
+	if fn != nil {
+		fn(change)
+	}
+}
+
+// install makes relay the live connection and, for pooled relays, starts supervising it.
+// Must be called with rc.mu held.
+func (c *Client) install(rc *relayConn, relay *relaySocket) {
+	rc.stopSupervisor()
+	rc.relay = relay
+	if rc.transient {
+		return
+	}
+	rc.stop = make(chan struct{})
+	go c.supervise(rc, relay, rc.stop)
+}
+
+// supervise waits for a connection to drop and reconnects with backoff until stopped
+func (c *Client) supervise(rc *relayConn, relay *relaySocket, stop chan struct{}) {
+	select {
+	case <-relay.Done():
+	case <-stop:
+		return
+	}
+
+	c.mu.Lock()
+	policy := c.policy
+	timeout := c.timeout
+	c.mu.Unlock()
+
+	var lastErr error
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		if stopped(stop) {
+			return
+		}
+		c.notify(rc, StateChange{Relay: rc.url, State: Reconnecting, Attempt: attempt, Err: lastErr})
+
+		select {
+		case <-time.After(policy.backoff(attempt)):
+		case <-stop:
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		newRelay, err := dialRelay(ctx, rc.url)
+		cancel()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		rc.mu.Lock()
+		if stopped(stop) {
+			// Closed or replaced while we were dialing
+			rc.mu.Unlock()
+			newRelay.Close()
+			return
+		}
+		c.install(rc, newRelay)
+		rc.mu.Unlock()
+
+		c.notify(rc, StateChange{Relay: rc.url, State: Connected, Attempt: attempt})
+		return
+	}
+
+	if !stopped(stop) {
+		c.notify(rc, StateChange{Relay: rc.url, State: Failed, Attempt: policy.MaxAttempts, Err: lastErr})
+	}
+}
+
+// stopped reports whether a supervisor's stop channel has been closed
+func stopped(stop chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// stopSupervisor stops the goroutine watching the current connection. Must be called with rc.mu held.
+func (rc *relayConn) stopSupervisor() {
+	if rc.stop != nil {
+		close(rc.stop)
+		rc.stop = nil
+	}
+}
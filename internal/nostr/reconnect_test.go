@@ -0,0 +1,239 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestBackoffGrowsAndCaps(t *testing.T) {
+	policy := ReconnectPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		Multiplier:     2,
+	}
+
+	want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+	for i, w := range want {
+		if got := policy.backoff(i + 1); got != w*time.Millisecond {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w*time.Millisecond)
+		}
+	}
+}
+
+func TestBackoffJitterStaysInRange(t *testing.T) {
+	policy := ReconnectPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+
+	varied := false
+	for i := 0; i < 200; i++ {
+		got := policy.backoff(2)
+		if got < 1600*time.Millisecond || got > 2400*time.Millisecond {
+			t.Fatalf("backoff(2) = %v, want within 2s ±20%%", got)
+		}
+		if got != 2*time.Second {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Error("jitter never changed the delay")
+	}
+}
+
+// stateRecorder collects state changes delivered to the client callback
+func stateRecorder(client *Client) chan StateChange {
+	changes := make(chan StateChange, 64)
+	client.OnStateChange(func(change StateChange) {
+		changes <- change
+	})
+	return changes
+}
+
+// waitForState reads changes until one with the wanted state arrives
+func waitForState(t *testing.T, changes chan StateChange, want ConnState) StateChange {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case change := <-changes:
+			if change.State == want {
+				return change
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for state %v", want)
+		}
+	}
+}
+
+func TestReconnectAfterDrop(t *testing.T) {
+	relay := newTestRelay(t)
+	client := newTestClient(t)
+	client.SetReconnectPolicy(ReconnectPolicy{InitialBackoff: 10 * time.Millisecond, Multiplier: 2})
+	changes := stateRecorder(client)
+
+	if err := client.ConnectToRelay(context.Background(), relay.URL); err != nil {
+		t.Fatalf("ConnectToRelay: %v", err)
+	}
+	waitForState(t, changes, Connected)
+
+	relay.DropConnections()
+
+	if change := waitForState(t, changes, Reconnecting); change.Attempt != 1 {
+		t.Errorf("first reconnect attempt = %d, want 1", change.Attempt)
+	}
+	waitForState(t, changes, Connected)
+	if state := client.State(relay.URL); state != Connected {
+		t.Errorf("State = %v, want connected", state)
+	}
+
+	// The new connection works
+	if _, err := client.SendPublicPost(context.Background(), "after reconnect", "test", nil, 0); err != nil {
+		t.Fatalf("SendPublicPost: %v", err)
+	}
+}
+
+func TestReconnectGivesUpAfterMaxAttempts(t *testing.T) {
+	relay := newTestRelay(t)
+	client := newTestClient(t)
+	client.SetReconnectPolicy(ReconnectPolicy{InitialBackoff: 5 * time.Millisecond, Multiplier: 1, MaxAttempts: 3})
+	changes := stateRecorder(client)
+
+	if err := client.ConnectToRelay(context.Background(), relay.URL); err != nil {
+		t.Fatalf("ConnectToRelay: %v", err)
+	}
+	waitForState(t, changes, Connected)
+
+	// Stopping the server makes every redial fail
+	relay.Close()
+
+	attempts := 0
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case change := <-changes:
+			switch change.State {
+			case Reconnecting:
+				attempts++
+			case Failed:
+				if attempts != 3 || change.Attempt != 3 {
+					t.Fatalf("failed after %d attempts (reported %d), want 3", attempts, change.Attempt)
+				}
+				if state := client.State(relay.URL); state != Failed {
+					t.Errorf("State = %v, want failed", state)
+				}
+				return
+			case Connected:
+				t.Fatal("reconnected to a stopped relay")
+			}
+		case <-timeout:
+			t.Fatalf("never gave up, %d attempts so far", attempts)
+		}
+	}
+}
+
+// newStalledRelay returns the ws:// URL of a listener that accepts connections
+// but never completes the websocket handshake
+func newStalledRelay(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		held := []net.Conn{}
+		defer func() {
+			for _, conn := range held {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			held = append(held, conn)
+		}
+	}()
+
+	return "ws://" + listener.Addr().String()
+}
+
+func TestStateDoesNotBlockOnDial(t *testing.T) {
+	url := newStalledRelay(t)
+	client := newTestClient(t, url)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go client.ConnectToRelay(ctx, url)
+	time.Sleep(100 * time.Millisecond)
+
+	done := make(chan ConnState)
+	go func() { done <- client.State(url) }()
+	select {
+	case state := <-done:
+		if state != Disconnected {
+			t.Errorf("State = %v while dialing, want disconnected", state)
+		}
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("State blocked while the relay was being dialed")
+	}
+}
+
+func TestRemoveRelayAbortsDial(t *testing.T) {
+	url := newStalledRelay(t)
+	client := newTestClient(t)
+	changes := stateRecorder(client)
+
+	done := make(chan error, 1)
+	go func() { done <- client.ConnectToRelay(context.Background(), url) }()
+	time.Sleep(100 * time.Millisecond)
+
+	client.RemoveRelay(url)
+	select {
+	case err := <-done:
+		var relayErr *RelayError
+		if !errors.As(err, &relayErr) || !errors.Is(relayErr.Err, ErrConnectionClosed) {
+			t.Errorf("ConnectToRelay error = %v, want ErrConnectionClosed", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("dial kept running after the relay was removed")
+	}
+
+	// The aborted dial neither reports a failure nor brings the relay back
+	select {
+	case change := <-changes:
+		t.Errorf("state change %+v after the relay was removed", change)
+	default:
+	}
+	if relays := client.Relays(); len(relays) != 0 {
+		t.Errorf("pool = %v, want empty", relays)
+	}
+}
+
+func TestRemoveRelayStopsReconnecting(t *testing.T) {
+	relay := newTestRelay(t)
+	client := newTestClient(t)
+	client.SetReconnectPolicy(ReconnectPolicy{InitialBackoff: 10 * time.Millisecond, Multiplier: 1})
+
+	if err := client.ConnectToRelay(context.Background(), relay.URL); err != nil {
+		t.Fatalf("ConnectToRelay: %v", err)
+	}
+	client.RemoveRelay(relay.URL)
+	changes := stateRecorder(client)
+
+	// With the relay removed nothing is left to notice the drop and redial
+	relay.DropConnections()
+	select {
+	case change := <-changes:
+		t.Errorf("state change %+v after the relay was removed", change)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,131 @@
+package nostr
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// DefaultRelayListTTL is how long a discovered relay list is reused before it is fetched again
+const DefaultRelayListTTL = 10 * time.Minute
+
+// relayListCache remembers the relay lists published by other users.
+// An empty list is cached too, so users without one aren't looked up on every send.
+type relayListCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]relayListEntry
+}
+
+type relayListEntry struct {
+	relays  []string
+	expires time.Time
+}
+
+func newRelayListCache(ttl time.Duration) *relayListCache {
+	return &relayListCache{
+		ttl:     ttl,
+		entries: make(map[string]relayListEntry),
+	}
+}
+
+// get returns a cached relay list and whether it was found and still fresh
+func (rc *relayListCache) get(pubKey string) ([]string, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[pubKey]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.relays, true
+}
+
+// set stores a relay list for the cache's TTL; a zero TTL disables caching
+func (rc *relayListCache) set(pubKey string, relays []string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.ttl <= 0 {
+		return
+	}
+	rc.entries[pubKey] = relayListEntry{
+		relays:  relays,
+		expires: time.Now().Add(rc.ttl),
+	}
+}
+
+// delete drops a cached entry
+func (rc *relayListCache) delete(pubKey string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	delete(rc.entries, pubKey)
+}
+
+// setTTL changes the TTL and drops every cached entry
+func (rc *relayListCache) setTTL(ttl time.Duration) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.ttl = ttl
+	rc.entries = make(map[string]relayListEntry)
+}
+
+// SetRelayListTTL changes how long discovered relay lists are cached, 0 disables the cache
+func (c *Client) SetRelayListTTL(ttl time.Duration) {
+	c.dmRelays.setTTL(ttl)
+	c.readRelays.setTTL(ttl)
+	c.writeRelays.setTTL(ttl)
+}
+
+// newestEvent returns the most recent event, as relays may hold different versions of a replaceable event
+func newestEvent(events []*nostr.Event) *nostr.Event {
+	var newest *nostr.Event
+	for _, ev := range events {
+		if newest == nil || ev.CreatedAt > newest.CreatedAt {
+			newest = ev
+		}
+	}
+	return newest
+}
+
+// fetchLatest returns the newest event of a replaceable kind by pubKey across the pool, or nil if no relay has one
+func (c *Client) fetchLatest(ctx context.Context, kind int, pubKey string) (*nostr.Event, error) {
+	events, err := c.query(ctx, nostr.Filter{
+		Kinds:   []int{kind},
+		Authors: []string{pubKey},
+		Limit:   1,
+	}, c.Relays())
+	if err != nil {
+		return nil, err
+	}
+
+	return newestEvent(events), nil
+}
+
+// fetchLatestStrict is fetchLatest for an event we are about to replace. It fails with ErrIncompleteRead
+// if any relay in the pool can't be read, rather than mistaking a missing version for none at all.
+func (c *Client) fetchLatestStrict(ctx context.Context, kind int, pubKey string) (*nostr.Event, error) {
+	events, err := c.queryStrict(ctx, nostr.Filter{
+		Kinds:   []int{kind},
+		Authors: []string{pubKey},
+		Limit:   1,
+	}, c.Relays())
+	if err != nil {
+		return nil, err
+	}
+
+	return newestEvent(events), nil
+}
+
+// replacementTimestamp returns a created_at that is now, or later than previous if our clock is behind,
+// so a replaceable event always supersedes the version it was built from
+func replacementTimestamp(previous *nostr.Event) nostr.Timestamp {
+	now := nostr.Timestamp(time.Now().Unix())
+	if previous != nil && previous.CreatedAt >= now {
+		return previous.CreatedAt + 1
+	}
+	return now
+}
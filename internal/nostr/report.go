@@ -0,0 +1,88 @@
+package nostr
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+var (
+	ErrPublishFailed = errors.New("no relay accepted the event")
+)
+
+// RelayResult is the outcome of publishing one event to one relay
+type RelayResult struct {
+	Relay    string        `json:"relay"`
+	Accepted bool          `json:"accepted"`
+	Message  string        `json:"message,omitempty"`
+	Latency  time.Duration `json:"latency_ns"`
+}
+
+// EventReport lists the per-relay results for a single published event
+type EventReport struct {
+	EventID   string        `json:"event_id"`
+	NoteID    string        `json:"note_id"`
+	Kind      int           `json:"kind"`
+	Recipient string        `json:"recipient,omitempty"`
+	Results   []RelayResult `json:"results"`
+}
+
+// PublishReport describes where every event of a publish operation landed
+type PublishReport struct {
+	// Transport is set by SendDM to the DM mode that was used, "nip17" or "nip04"
+	Transport string         `json:"transport,omitempty"`
+	Events    []*EventReport `json:"events"`
+	// Warnings lists problems that didn't stop the publish, e.g. relays that may ignore expiration
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// newEventReport creates an empty report for an event ID
+func newEventReport(eventID string, kind int) *EventReport {
+	// Encoding only fails for malformed IDs, in which case the hex ID is still reported
+	noteID, _ := nip19.EncodeNote(eventID)
+	return &EventReport{
+		EventID: eventID,
+		NoteID:  noteID,
+		Kind:    kind,
+		Results: []RelayResult{},
+	}
+}
+
+// AcceptedCount returns how many relays accepted the event
+func (r *EventReport) AcceptedCount() int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Accepted {
+			count++
+		}
+	}
+	return count
+}
+
+// NoteID returns the bech32 note ID of the first event in the report
+func (r *PublishReport) NoteID() string {
+	if len(r.Events) == 0 {
+		return ""
+	}
+	return r.Events[0].NoteID
+}
+
+// add appends an event report and returns the receiver for chaining
+func (r *PublishReport) add(event *EventReport) *PublishReport {
+	r.Events = append(r.Events, event)
+	return r
+}
+
+// err returns ErrPublishFailed if any event in the report was not accepted by a single relay
+func (r *PublishReport) err() error {
+	if len(r.Events) == 0 {
+		return ErrPublishFailed
+	}
+	for _, event := range r.Events {
+		if event.AcceptedCount() == 0 {
+			return ErrPublishFailed
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package nostr
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// Repost publishes a NIP-18 repost of an event referenced by hex ID, note1 or nevent1.
+// Kind 1 notes get a kind 6 repost, anything else a kind 16 generic repost. The original
+// event is embedded in the content so clients can render it without fetching.
+func (c *Client) Repost(ctx context.Context, eventRef string) (*PublishReport, error) {
+	target, err := c.FetchEvent(ctx, eventRef)
+	if err != nil {
+		return nil, err
+	}
+	relayHint := c.eventRelayHint(eventRef)
+
+	kind := nostr.KindRepost
+	if target.Kind != nostr.KindTextNote {
+		kind = nostr.KindGenericRepost
+	}
+
+	// Create the event
+	ev := nostr.Event{
+		PubKey:    c.pubKey,
+		CreatedAt: nostr.Now(),
+		Kind:      kind,
+		Tags: nostr.Tags{
+			{"e", target.ID, relayHint},
+			{"p", target.PubKey},
+		},
+		Content: target.String(),
+	}
+
+	// Generic reposts name the kind, and point at the address of addressable events
+	if kind == nostr.KindGenericRepost {
+		if address := addressTag(target); address != "" {
+			ev.Tags = append(ev.Tags, nostr.Tag{"a", address, relayHint})
+		}
+		ev.Tags = append(ev.Tags, nostr.Tag{"k", strconv.Itoa(target.Kind)})
+	}
+
+	// Sign the event
+	err = c.signer.SignEvent(ctx, &ev)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish the event to our relays and the author's inbox relays
+	return c.publishRouted(ctx, &ev)
+}
+
+// QuotePost publishes a kind 1 post quoting an event referenced by hex ID, note1 or nevent1.
+// A nostr:nevent1 reference is appended to the message and q and p tags point at the quoted
+// event and its author. The other arguments are as for SendPublicPost.
+func (c *Client) QuotePost(ctx context.Context, message, quoteRef, clientID string, tags nostr.Tags, expiration time.Duration) (*PublishReport, error) {
+	quoted, err := c.FetchEvent(ctx, quoteRef)
+	if err != nil {
+		return nil, err
+	}
+	relayHint := c.eventRelayHint(quoteRef)
+
+	relays := []string{}
+	if relayHint != "" {
+		relays = append(relays, relayHint)
+	}
+	nevent, err := nip19.EncodeEvent(quoted.ID, relays, quoted.PubKey)
+	if err != nil {
+		return nil, err
+	}
+
+	content := strings.TrimSpace(message)
+	if content != "" {
+		content += "\n\n"
+	}
+	content += "nostr:" + nevent
+
+	quoteTags := nostr.Tags{{"q", quoted.ID, relayHint, quoted.PubKey}}
+	if quoted.PubKey != c.pubKey {
+		quoteTags = append(quoteTags, nostr.Tag{"p", quoted.PubKey})
+	}
+
+	return c.SendPublicPost(ctx, content, clientID, append(quoteTags, tags...), expiration)
+}
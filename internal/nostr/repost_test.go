@@ -0,0 +1,131 @@
+package nostr
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+func TestRepost(t *testing.T) {
+	relay := newTestRelay(t)
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+	hint := nostr.NormalizeURL(relay.URL)
+
+	note := postNote(t, alice, "repost me")
+	articleReport, _, err := alice.PublishArticle(context.Background(), &Article{Identifier: "hello", Title: "Hello", Content: "Body"}, false, "")
+	if err != nil {
+		t.Fatalf("PublishArticle: %v", err)
+	}
+	article := fetchByID(t, alice, articleReport.Events[0].EventID)
+
+	tests := []struct {
+		name   string
+		target *nostr.Event
+		kind   int
+		want   nostr.Tags
+	}{
+		{
+			name:   "note",
+			target: note,
+			kind:   nostr.KindRepost,
+			want:   nostr.Tags{{"e", note.ID, hint}, {"p", alice.GetPublicKey()}},
+		},
+		{
+			name:   "article",
+			target: article,
+			kind:   nostr.KindGenericRepost,
+			want: nostr.Tags{
+				{"e", article.ID, hint},
+				{"p", alice.GetPublicKey()},
+				{"a", "30023:" + alice.GetPublicKey() + ":hello", hint},
+				{"k", "30023"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := bob.Repost(context.Background(), tt.target.ID)
+			if err != nil {
+				t.Fatalf("Repost: %v", err)
+			}
+			repost := fetchByID(t, bob, report.Events[0].EventID)
+
+			if repost.Kind != tt.kind {
+				t.Errorf("kind = %d, want %d", repost.Kind, tt.kind)
+			}
+			if !slices.EqualFunc(repost.Tags, tt.want, slices.Equal) {
+				t.Errorf("tags = %q, want %q", repost.Tags, tt.want)
+			}
+
+			// The original is embedded and still verifies
+			var embedded nostr.Event
+			if err := embedded.UnmarshalJSON([]byte(repost.Content)); err != nil {
+				t.Fatalf("content is not an event: %v", err)
+			}
+			if ok, _ := embedded.CheckSignature(); embedded.ID != tt.target.ID || !embedded.CheckID() || !ok {
+				t.Errorf("embedded event = %s, want a valid copy of %s", embedded.ID, tt.target.ID)
+			}
+		})
+	}
+}
+
+func TestQuotePost(t *testing.T) {
+	relay := newTestRelay(t)
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+	note := postNote(t, alice, "quote me")
+
+	// The nevent's relay hint carries over to the q tag and the embedded reference
+	ref, _ := nip19.EncodeEvent(note.ID, []string{"wss://hint.example"}, alice.GetPublicKey())
+	report, err := bob.QuotePost(context.Background(), "well said", ref, "", nostr.Tags{{"t", "quotes"}}, 0)
+	if err != nil {
+		t.Fatalf("QuotePost: %v", err)
+	}
+	quote := fetchByID(t, bob, report.Events[0].EventID)
+
+	if quote.Kind != nostr.KindTextNote {
+		t.Errorf("kind = %d, want 1", quote.Kind)
+	}
+	if q := quote.Tags.Find("q"); !slices.Equal(q, nostr.Tag{"q", note.ID, "wss://hint.example", alice.GetPublicKey()}) {
+		t.Errorf("q tag = %q, want [q id relay pubkey]", q)
+	}
+	if quote.Tags.FindWithValue("p", alice.GetPublicKey()) == nil || quote.Tags.FindWithValue("t", "quotes") == nil {
+		t.Errorf("tags = %q, want the quoted author and the extra tag", quote.Tags)
+	}
+
+	message, reference, ok := strings.Cut(quote.Content, "\n\nnostr:")
+	if !ok || message != "well said" {
+		t.Fatalf("content = %q, want the message and a nostr: reference", quote.Content)
+	}
+	_, decoded, err := nip19.Decode(reference)
+	pointer, _ := decoded.(nostr.EventPointer)
+	if err != nil || pointer.ID != note.ID || pointer.Author != alice.GetPublicKey() || !slices.Equal(pointer.Relays, []string{"wss://hint.example"}) {
+		t.Errorf("reference = %q decodes to %+v, want the quoted note with its author and relay", reference, pointer)
+	}
+}
+
+func TestQuoteOwnPost(t *testing.T) {
+	relay := newTestRelay(t)
+	alice := newTestClient(t, relay.URL)
+	note := postNote(t, alice, "quote me")
+
+	report, err := alice.QuotePost(context.Background(), "", note.ID, "", nil, 0)
+	if err != nil {
+		t.Fatalf("QuotePost: %v", err)
+	}
+	quote := fetchByID(t, alice, report.Events[0].EventID)
+
+	// Nobody to notify, and only the reference in the content
+	if p := quote.Tags.Find("p"); p != nil {
+		t.Errorf("p tag = %q, want none when quoting yourself", p)
+	}
+	if !strings.HasPrefix(quote.Content, "nostr:nevent1") {
+		t.Errorf("content = %q, want only the reference", quote.Content)
+	}
+}
@@ -0,0 +1,177 @@
+package nostr
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrRoomNotFound  = errors.New("room not found")
+	ErrAmbiguousRoom = errors.New("room ID prefix matches more than one room")
+)
+
+// Room is a NIP-17 conversation, identified by the set of its participants (sender plus p tags).
+// Adding or removing a participant makes a different room.
+type Room struct {
+	ID           string
+	Participants []string // Sorted hex public keys, including our own
+	Subject      string   // The newest subject set in the room
+	Messages     []*NIP17Message
+	LastActivity nostr.Timestamp
+}
+
+// RoomID returns the stable identifier of a participant set: the start of the SHA-256 of the sorted keys
+func RoomID(participants []string) string {
+	sorted := sortedParticipants(participants)
+	hash := sha256.Sum256([]byte(strings.Join(sorted, ",")))
+	return hex.EncodeToString(hash[:8])
+}
+
+// Participants returns the sorted, deduplicated participant set of a message
+func (m *NIP17Message) Participants() []string {
+	return sortedParticipants(append([]string{m.Sender}, m.Recipients...))
+}
+
+// Others returns the room's participants other than pubKey, i.e. the recipients of a message sent by pubKey
+func (r *Room) Others(pubKey string) []string {
+	others := []string{}
+	for _, participant := range r.Participants {
+		if participant != pubKey {
+			others = append(others, participant)
+		}
+	}
+	return others
+}
+
+// NewRoom creates an empty room for us and the given participants (hex, npub, or nprofile)
+func (c *Client) NewRoom(participantKeys []string) (*Room, error) {
+	participants := []string{c.pubKey}
+	for _, key := range participantKeys {
+		pubKey, err := DecodePublicKey(key)
+		if err != nil {
+			return nil, err
+		}
+		participants = append(participants, pubKey)
+	}
+
+	participants = sortedParticipants(participants)
+	return &Room{
+		ID:           RoomID(participants),
+		Participants: participants,
+		Messages:     []*NIP17Message{},
+	}, nil
+}
+
+// GroupRooms sorts messages into rooms, most recently active first
+func GroupRooms(messages []*NIP17Message) []*Room {
+	rooms := make(map[string]*Room)
+	order := []*Room{}
+
+	for _, msg := range messages {
+		participants := msg.Participants()
+		id := RoomID(participants)
+
+		room, ok := rooms[id]
+		if !ok {
+			room = &Room{ID: id, Participants: participants}
+			rooms[id] = room
+			order = append(order, room)
+		}
+		room.add(msg)
+	}
+
+	for _, room := range order {
+		sort.SliceStable(room.Messages, func(i, j int) bool {
+			return room.Messages[i].CreatedAt < room.Messages[j].CreatedAt
+		})
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].LastActivity > order[j].LastActivity
+	})
+
+	return order
+}
+
+// add appends a message and updates the room's subject and activity time
+func (r *Room) add(msg *NIP17Message) {
+	r.Messages = append(r.Messages, msg)
+
+	if msg.CreatedAt >= r.LastActivity {
+		r.LastActivity = msg.CreatedAt
+	}
+
+	// The newest subject wins, whoever set it
+	if msg.Subject != "" {
+		newest := true
+		for _, other := range r.Messages {
+			if other.Subject != "" && other.CreatedAt > msg.CreatedAt {
+				newest = false
+				break
+			}
+		}
+		if newest {
+			r.Subject = msg.Subject
+		}
+	}
+}
+
+// FetchRooms fetches our NIP-17 inbox and groups it into rooms
+func (c *Client) FetchRooms(ctx context.Context, since time.Time) ([]*Room, error) {
+	messages, err := c.FetchNIP17Messages(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	return GroupRooms(messages), nil
+}
+
+// FindRoom returns the room whose ID starts with idPrefix
+func FindRoom(rooms []*Room, idPrefix string) (*Room, error) {
+	var found *Room
+	for _, room := range rooms {
+		if !strings.HasPrefix(room.ID, idPrefix) {
+			continue
+		}
+		if found != nil {
+			return nil, ErrAmbiguousRoom
+		}
+		found = room
+	}
+
+	if found == nil {
+		return nil, ErrRoomNotFound
+	}
+	return found, nil
+}
+
+// SendToRoom sends a NIP-17 message to every other participant of a room.
+// A non-empty subject renames the room for everyone.
+func (c *Client) SendToRoom(ctx context.Context, room *Room, message, replyToID, subject, clientID string,
+	expiration time.Duration) (*PublishReport, error) {
+
+	return c.SendNIP17DirectMessage(ctx, room.Others(c.pubKey), message, replyToID, subject, clientID, expiration)
+}
+
+// sortedParticipants deduplicates and sorts a list of hex public keys
+func sortedParticipants(pubKeys []string) []string {
+	set := make(map[string]bool)
+	participants := []string{}
+	for _, pubKey := range pubKeys {
+		if set[pubKey] {
+			continue
+		}
+		set[pubKey] = true
+		participants = append(participants, pubKey)
+	}
+
+	sort.Strings(participants)
+	return participants
+}
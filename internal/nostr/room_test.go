@@ -0,0 +1,154 @@
+package nostr
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+func TestRoomID(t *testing.T) {
+	alice := strings.Repeat("a", 64)
+	bob := strings.Repeat("b", 64)
+	carol := strings.Repeat("c", 64)
+
+	tests := []struct {
+		name string
+		a, b []string
+		same bool
+	}{
+		{"order is ignored", []string{alice, bob, carol}, []string{carol, alice, bob}, true},
+		{"duplicates are ignored", []string{alice, bob, bob}, []string{bob, alice}, true},
+		{"another participant is another room", []string{alice, bob}, []string{alice, bob, carol}, false},
+		{"other participants are another room", []string{alice, bob}, []string{alice, carol}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := RoomID(tt.a) == RoomID(tt.b); same != tt.same {
+				t.Errorf("RoomID(%v) == RoomID(%v) is %v, want %v", tt.a, tt.b, same, tt.same)
+			}
+		})
+	}
+}
+
+func TestGroupRooms(t *testing.T) {
+	alice := strings.Repeat("a", 64)
+	bob := strings.Repeat("b", 64)
+	carol := strings.Repeat("c", 64)
+
+	message := func(id, sender string, recipients []string, createdAt nostr.Timestamp, subject string) *NIP17Message {
+		return &NIP17Message{ID: id, Sender: sender, Recipients: recipients, CreatedAt: createdAt, Subject: subject}
+	}
+
+	tests := []struct {
+		name     string
+		messages []*NIP17Message
+		// rooms lists each room's message IDs, most recently active room first
+		rooms    [][]string
+		subjects []string
+	}{
+		{
+			name: "both directions of a conversation are one room",
+			messages: []*NIP17Message{
+				message("1", alice, []string{bob}, 10, ""),
+				message("2", bob, []string{alice}, 20, ""),
+			},
+			rooms:    [][]string{{"1", "2"}},
+			subjects: []string{""},
+		},
+		{
+			name: "group members in any order",
+			messages: []*NIP17Message{
+				message("1", alice, []string{bob, carol}, 10, ""),
+				message("2", carol, []string{bob, alice}, 20, ""),
+				message("3", bob, []string{alice}, 30, ""),
+			},
+			rooms:    [][]string{{"3"}, {"1", "2"}},
+			subjects: []string{"", ""},
+		},
+		{
+			name: "messages sorted by time, rooms by last activity",
+			messages: []*NIP17Message{
+				message("3", alice, []string{bob}, 30, ""),
+				message("1", alice, []string{bob}, 10, ""),
+				message("2", alice, []string{carol}, 20, ""),
+			},
+			rooms:    [][]string{{"1", "3"}, {"2"}},
+			subjects: []string{"", ""},
+		},
+		{
+			name: "newest subject wins whoever set it",
+			messages: []*NIP17Message{
+				message("1", alice, []string{bob}, 10, "Plans"),
+				message("2", bob, []string{alice}, 20, "New plans"),
+				message("3", alice, []string{bob}, 30, ""),
+			},
+			rooms:    [][]string{{"1", "2", "3"}},
+			subjects: []string{"New plans"},
+		},
+		{
+			name: "an older subject arriving late doesn't win",
+			messages: []*NIP17Message{
+				message("2", bob, []string{alice}, 20, "New plans"),
+				message("1", alice, []string{bob}, 10, "Plans"),
+			},
+			rooms:    [][]string{{"1", "2"}},
+			subjects: []string{"New plans"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rooms := GroupRooms(tt.messages)
+			if len(rooms) != len(tt.rooms) {
+				t.Fatalf("got %d rooms, want %d", len(rooms), len(tt.rooms))
+			}
+			for i, room := range rooms {
+				ids := []string{}
+				for _, msg := range room.Messages {
+					ids = append(ids, msg.ID)
+				}
+				if !slices.Equal(ids, tt.rooms[i]) {
+					t.Errorf("room %d messages = %v, want %v", i, ids, tt.rooms[i])
+				}
+				if room.Subject != tt.subjects[i] {
+					t.Errorf("room %d subject = %q, want %q", i, room.Subject, tt.subjects[i])
+				}
+				if room.ID != RoomID(room.Participants) {
+					t.Errorf("room %d ID = %s, want the ID of its participants", i, room.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestFindRoom(t *testing.T) {
+	rooms := []*Room{{ID: "abc123"}, {ID: "abd456"}, {ID: "ffff00"}}
+
+	tests := []struct {
+		name   string
+		prefix string
+		want   string
+		err    error
+	}{
+		{"full ID", "abd456", "abd456", nil},
+		{"unique prefix", "ff", "ffff00", nil},
+		{"ambiguous prefix", "ab", "", ErrAmbiguousRoom},
+		{"no match", "00", "", ErrRoomNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			room, err := FindRoom(rooms, tt.prefix)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("FindRoom(%q) error = %v, want %v", tt.prefix, err, tt.err)
+			}
+			if err == nil && room.ID != tt.want {
+				t.Errorf("FindRoom(%q) = %s, want %s", tt.prefix, room.ID, tt.want)
+			}
+		})
+	}
+}
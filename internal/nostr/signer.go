@@ -0,0 +1,114 @@
+package nostr
+
+import (
+	"context"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip44"
+)
+
+// Signer signs events and encrypts/decrypts payloads on behalf of a key.
+// Implementations may keep the key in memory or delegate to a remote signer.
+type Signer interface {
+	// GetPublicKey returns the signer's public key in hex format
+	GetPublicKey(ctx context.Context) (string, error)
+
+	// SignEvent sets the event's pubkey, ID and signature
+	SignEvent(ctx context.Context, event *nostr.Event) error
+
+	// NIP04Encrypt encrypts plaintext for a recipient using NIP-04
+	NIP04Encrypt(ctx context.Context, plaintext, recipientPubKey string) (string, error)
+
+	// NIP04Decrypt decrypts a NIP-04 payload exchanged with a counterparty
+	NIP04Decrypt(ctx context.Context, ciphertext, counterpartyPubKey string) (string, error)
+
+	// NIP44Encrypt encrypts plaintext for a recipient using NIP-44
+	NIP44Encrypt(ctx context.Context, plaintext, recipientPubKey string) (string, error)
+
+	// NIP44Decrypt decrypts a NIP-44 payload exchanged with a counterparty
+	NIP44Decrypt(ctx context.Context, ciphertext, counterpartyPubKey string) (string, error)
+}
+
+// readOnlySigner is the Signer of a read-only client, it refuses everything that needs a key
+type readOnlySigner struct{}
+
+func (readOnlySigner) GetPublicKey(ctx context.Context) (string, error) {
+	return "", ErrNoSigner
+}
+
+func (readOnlySigner) SignEvent(ctx context.Context, event *nostr.Event) error {
+	return ErrNoSigner
+}
+
+func (readOnlySigner) NIP04Encrypt(ctx context.Context, plaintext, recipientPubKey string) (string, error) {
+	return "", ErrNoSigner
+}
+
+func (readOnlySigner) NIP04Decrypt(ctx context.Context, ciphertext, counterpartyPubKey string) (string, error) {
+	return "", ErrNoSigner
+}
+
+func (readOnlySigner) NIP44Encrypt(ctx context.Context, plaintext, recipientPubKey string) (string, error) {
+	return "", ErrNoSigner
+}
+
+func (readOnlySigner) NIP44Decrypt(ctx context.Context, ciphertext, counterpartyPubKey string) (string, error) {
+	return "", ErrNoSigner
+}
+
+// KeySigner is a Signer backed by a private key held in memory
+type KeySigner struct {
+	sk     string
+	pubKey string
+}
+
+// NewKeySigner creates a Signer from a hex private key
+func NewKeySigner(privateKeyHex string) (*KeySigner, error) {
+	pubKey, err := GetPublicKeyFromPrivate(privateKeyHex)
+	if err != nil {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	return &KeySigner{
+		sk:     privateKeyHex,
+		pubKey: pubKey,
+	}, nil
+}
+
+// GetPublicKey returns the public key derived from the private key
+func (s *KeySigner) GetPublicKey(ctx context.Context) (string, error) {
+	return s.pubKey, nil
+}
+
+// SignEvent signs the event with the private key
+func (s *KeySigner) SignEvent(ctx context.Context, event *nostr.Event) error {
+	return event.Sign(s.sk)
+}
+
+// NIP04Encrypt encrypts plaintext for a recipient using NIP-04
+func (s *KeySigner) NIP04Encrypt(ctx context.Context, plaintext, recipientPubKey string) (string, error) {
+	return EncryptDirectMessage(plaintext, recipientPubKey, s.sk)
+}
+
+// NIP04Decrypt decrypts a NIP-04 payload exchanged with a counterparty
+func (s *KeySigner) NIP04Decrypt(ctx context.Context, ciphertext, counterpartyPubKey string) (string, error) {
+	return DecryptDirectMessage(ciphertext, counterpartyPubKey, s.sk)
+}
+
+// NIP44Encrypt encrypts plaintext for a recipient using NIP-44
+func (s *KeySigner) NIP44Encrypt(ctx context.Context, plaintext, recipientPubKey string) (string, error) {
+	conversationKey, err := nip44.GenerateConversationKey(recipientPubKey, s.sk)
+	if err != nil {
+		return "", err
+	}
+	return nip44.Encrypt(plaintext, conversationKey)
+}
+
+// NIP44Decrypt decrypts a NIP-44 payload exchanged with a counterparty
+func (s *KeySigner) NIP44Decrypt(ctx context.Context, ciphertext, counterpartyPubKey string) (string, error) {
+	conversationKey, err := nip44.GenerateConversationKey(counterpartyPubKey, s.sk)
+	if err != nil {
+		return "", err
+	}
+	return nip44.Decrypt(ciphertext, conversationKey)
+}
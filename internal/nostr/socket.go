@@ -0,0 +1,311 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrConnectionClosed = errors.New("relay connection closed")
+)
+
+// relaySocket is a single NIP-01 websocket connection to a relay. One goroutine reads
+// every message and hands OKs, events, EOSEs and CLOSEDs to the calls waiting for them;
+// writes are serialized by writeMu. done is closed once the connection is gone, after
+// which every pending and future call fails.
+type relaySocket struct {
+	url     string
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	oks     map[string]chan nostr.OKEnvelope
+	subs    map[string]*socketSub
+	nextSub int
+	err     error
+
+	// closing is closed by Close so the reader stops delivering events nobody will read
+	closing   chan struct{}
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// socketSub is an open REQ. The reader delivers matching events on events, closes eose
+// at EOSE and ended when the relay sends CLOSED, with the reason in reason.
+type socketSub struct {
+	id     string
+	filter nostr.Filter
+	events chan *nostr.Event
+	eose   chan struct{}
+	ended  chan struct{}
+	reason string
+	// gone is closed by unsubscribe so the reader stops delivering to it
+	gone chan struct{}
+}
+
+// dialRelay opens a websocket to url and starts reading from it
+func dialRelay(ctx context.Context, url string) (*relaySocket, error) {
+	// The websocket dialer only applies ctx's deadline to the handshake, so close
+	// the connection ourselves if ctx is cancelled before the handshake completes
+	var stop func() bool
+	dialer := *websocket.DefaultDialer
+	dialer.NetDialContext = func(dialCtx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := (&net.Dialer{}).DialContext(dialCtx, network, addr)
+		if err == nil {
+			stop = context.AfterFunc(ctx, func() { conn.Close() })
+		}
+		return conn, err
+	}
+
+	ws, _, err := dialer.DialContext(ctx, url, nil)
+	if stop != nil && !stop() {
+		if ws != nil {
+			ws.Close()
+		}
+		return nil, fmt.Errorf("error opening websocket to '%s': %w", url, context.Cause(ctx))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening websocket to '%s': %w", url, err)
+	}
+
+	s := &relaySocket{
+		url:     url,
+		ws:      ws,
+		oks:     make(map[string]chan nostr.OKEnvelope),
+		subs:    make(map[string]*socketSub),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go s.read()
+	return s, nil
+}
+
+// IsConnected reports whether the connection is still open
+func (s *relaySocket) IsConnected() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Done returns a channel closed when the connection is gone
+func (s *relaySocket) Done() <-chan struct{} {
+	return s.done
+}
+
+// Close closes the connection and waits for the reader to exit
+func (s *relaySocket) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closing)
+		s.ws.Close()
+	})
+	<-s.done
+}
+
+// error returns why the connection is gone
+func (s *relaySocket) error() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Publish sends an event and waits for the relay's OK. A rejection is returned as an
+// error carrying the relay's reason.
+func (s *relaySocket) Publish(ctx context.Context, event nostr.Event) error {
+	ok := make(chan nostr.OKEnvelope, 1)
+	s.mu.Lock()
+	s.oks[event.ID] = ok
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.oks, event.ID)
+		s.mu.Unlock()
+	}()
+
+	if err := s.write(&nostr.EventEnvelope{Event: event}); err != nil {
+		return err
+	}
+
+	select {
+	case env := <-ok:
+		if !env.OK {
+			if env.Reason == "" {
+				return errors.New("rejected without a reason")
+			}
+			return errors.New(env.Reason)
+		}
+		return nil
+	case <-s.done:
+		return s.error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// QuerySync fetches the stored events matching filter. It returns at EOSE and fails
+// if the relay closes the subscription or the connection first.
+func (s *relaySocket) QuerySync(ctx context.Context, filter nostr.Filter) ([]*nostr.Event, error) {
+	sub, err := s.subscribe(filter)
+	if err != nil {
+		return nil, err
+	}
+	defer s.unsubscribe(sub)
+
+	events := []*nostr.Event{}
+	for {
+		select {
+		case event := <-sub.events:
+			events = append(events, event)
+		case <-sub.eose:
+			return events, nil
+		case <-sub.ended:
+			return nil, fmt.Errorf("subscription closed by relay: %s", sub.reason)
+		case <-s.done:
+			return nil, s.error()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// subscribe sends a REQ for filter. The caller must unsubscribe when done with it.
+func (s *relaySocket) subscribe(filter nostr.Filter) (*socketSub, error) {
+	s.mu.Lock()
+	s.nextSub++
+	sub := &socketSub{
+		id:     strconv.Itoa(s.nextSub),
+		filter: filter,
+		events: make(chan *nostr.Event),
+		eose:   make(chan struct{}),
+		ended:  make(chan struct{}),
+		gone:   make(chan struct{}),
+	}
+	s.subs[sub.id] = sub
+	s.mu.Unlock()
+
+	if err := s.write(&nostr.ReqEnvelope{SubscriptionID: sub.id, Filters: nostr.Filters{filter}}); err != nil {
+		s.unsubscribe(sub)
+		return nil, err
+	}
+	return sub, nil
+}
+
+// unsubscribe stops delivery to sub and, unless the relay already closed it, sends CLOSE
+func (s *relaySocket) unsubscribe(sub *socketSub) {
+	s.mu.Lock()
+	_, open := s.subs[sub.id]
+	if open {
+		delete(s.subs, sub.id)
+		close(sub.gone)
+	}
+	s.mu.Unlock()
+
+	if open && s.IsConnected() {
+		closeEnv := nostr.CloseEnvelope(sub.id)
+		s.write(&closeEnv)
+	}
+}
+
+func (s *relaySocket) write(envelope nostr.Envelope) error {
+	data, err := envelope.MarshalJSON()
+	if err != nil {
+		return err
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if !s.IsConnected() {
+		return s.error()
+	}
+	return s.ws.WriteMessage(websocket.TextMessage, data)
+}
+
+// read dispatches incoming messages until the connection fails or is closed
+func (s *relaySocket) read() {
+	parser := nostr.NewMessageParser()
+	for {
+		_, message, err := s.ws.ReadMessage()
+		if err != nil {
+			s.mu.Lock()
+			s.err = fmt.Errorf("%w: %v", ErrConnectionClosed, err)
+			s.mu.Unlock()
+			s.ws.Close()
+			close(s.done)
+			return
+		}
+
+		envelope, err := parser.ParseMessage(string(message))
+		if err != nil || envelope == nil {
+			continue
+		}
+
+		switch env := envelope.(type) {
+		case *nostr.OKEnvelope:
+			s.mu.Lock()
+			ok, found := s.oks[env.EventID]
+			s.mu.Unlock()
+			if found {
+				select {
+				case ok <- *env:
+				default:
+				}
+			}
+
+		case *nostr.EventEnvelope:
+			if env.SubscriptionID == nil {
+				continue
+			}
+			sub := s.sub(*env.SubscriptionID)
+			if sub == nil || !sub.filter.Matches(&env.Event) {
+				continue
+			}
+			if ok, _ := env.Event.CheckSignature(); !ok {
+				continue
+			}
+
+			event := env.Event
+			select {
+			case sub.events <- &event:
+			case <-sub.gone:
+			case <-s.closing:
+			}
+
+		case *nostr.EOSEEnvelope:
+			if sub := s.sub(string(*env)); sub != nil {
+				select {
+				case <-sub.eose:
+				default:
+					close(sub.eose)
+				}
+			}
+
+		case *nostr.ClosedEnvelope:
+			s.mu.Lock()
+			sub, found := s.subs[env.SubscriptionID]
+			if found {
+				delete(s.subs, env.SubscriptionID)
+				sub.reason = env.Reason
+				close(sub.ended)
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// sub returns the open subscription with the given ID, or nil
+func (s *relaySocket) sub(id string) *socketSub {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.subs[id]
+}
@@ -0,0 +1,178 @@
+package nostr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+var (
+	ErrInvalidTag = errors.New("invalid tag")
+)
+
+// addressPattern matches the kind:pubkey:d value of an a tag
+var addressPattern = regexp.MustCompile(`^[0-9]+:[0-9a-f]{64}:`)
+
+// ParseTag parses one tag written either as comma-separated fields, e.g. "p,<npub>,wss://relay,mention",
+// or as a JSON array of strings. Fields holding a NIP-19 entity are decoded; see decodeTagEntities.
+func ParseTag(spec string) (nostr.Tag, error) {
+	spec = strings.TrimSpace(spec)
+
+	var tag nostr.Tag
+	if strings.HasPrefix(spec, "[") {
+		if err := json.Unmarshal([]byte(spec), &tag); err != nil {
+			return nil, fmt.Errorf("%w %s: expected a JSON array of strings", ErrInvalidTag, spec)
+		}
+	} else {
+		tag = strings.Split(spec, ",")
+	}
+
+	return normalizeTag(tag)
+}
+
+// ParseTags parses a list of tags, given either as a JSON array of arrays, e.g. [["t","nostr"],["r","https://x"]],
+// or in the older key:value,key:value form. Pairs are split at their first colon so values such as URLs keep
+// theirs. An empty spec gives no tags.
+func ParseTags(spec string) (nostr.Tags, error) {
+	spec = strings.TrimSpace(spec)
+	tags := nostr.Tags{}
+	if spec == "" {
+		return tags, nil
+	}
+
+	raw := []nostr.Tag{}
+	if strings.HasPrefix(spec, "[") {
+		if err := json.Unmarshal([]byte(spec), &raw); err != nil {
+			return nil, fmt.Errorf("%w %s: expected a JSON array of string arrays", ErrInvalidTag, spec)
+		}
+	} else {
+		for _, pair := range strings.Split(spec, ",") {
+			key, value, found := strings.Cut(pair, ":")
+			if !found || strings.TrimSpace(key) == "" || strings.TrimSpace(value) == "" {
+				return nil, fmt.Errorf("%w %q: expected key:value", ErrInvalidTag, pair)
+			}
+			raw = append(raw, nostr.Tag{key, value})
+		}
+	}
+
+	for _, tag := range raw {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// normalizeTag trims the tag name, decodes NIP-19 entities and checks the values of well-known tags
+func normalizeTag(tag nostr.Tag) (nostr.Tag, error) {
+	if len(tag) == 0 {
+		return nil, fmt.Errorf("%w: empty tag", ErrInvalidTag)
+	}
+	tag[0] = strings.TrimSpace(tag[0])
+	if tag[0] == "" || strings.ContainsAny(tag[0], " \t\n") {
+		return nil, fmt.Errorf("%w %v: tag name must be a single word", ErrInvalidTag, []string(tag))
+	}
+
+	tag, err := decodeTagEntities(tag)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tag) < 2 {
+		return tag, nil
+	}
+	if tag[1] == "" {
+		return nil, fmt.Errorf("%w %v: tag value is empty", ErrInvalidTag, []string(tag))
+	}
+	switch tag[0] {
+	case "e", "q":
+		if !nostr.IsValid32ByteHex(tag[1]) && !(tag[0] == "q" && addressPattern.MatchString(tag[1])) {
+			return nil, fmt.Errorf("%w %v: expected an event ID, note or nevent", ErrInvalidTag, []string(tag))
+		}
+	case "p":
+		if !nostr.IsValidPublicKey(tag[1]) {
+			return nil, fmt.Errorf("%w %v: expected a public key, npub or nprofile", ErrInvalidTag, []string(tag))
+		}
+	case "a":
+		if !addressPattern.MatchString(tag[1]) {
+			return nil, fmt.Errorf("%w %v: expected kind:pubkey:d or naddr", ErrInvalidTag, []string(tag))
+		}
+	}
+	return tag, nil
+}
+
+// decodeTagEntities replaces NIP-19 entities (with or without nostr:) by their hex or address form.
+// Relay hints and authors carried by nprofile, nevent and naddr in the tag value fill the
+// tag's relay and author fields when those are empty. An e tag given an naddr becomes an a tag,
+// since addresses aren't event IDs. nsec is always refused.
+func decodeTagEntities(tag nostr.Tag) (nostr.Tag, error) {
+	for i := 1; i < len(tag); i++ {
+		value := strings.TrimSpace(tag[i])
+		prefix, decoded, err := nip19.Decode(strings.TrimPrefix(value, "nostr:"))
+		if err != nil {
+			tag[i] = value
+			continue
+		}
+
+		var relays []string
+		var author string
+		switch prefix {
+		case "npub", "note":
+			tag[i] = decoded.(string)
+		case "nprofile":
+			pointer := decoded.(nostr.ProfilePointer)
+			tag[i], relays = pointer.PublicKey, pointer.Relays
+		case "nevent":
+			pointer := decoded.(nostr.EventPointer)
+			tag[i], relays, author = pointer.ID, pointer.Relays, pointer.Author
+		case "naddr":
+			pointer := decoded.(nostr.EntityPointer)
+			tag[i] = strconv.Itoa(pointer.Kind) + ":" + pointer.PublicKey + ":" + pointer.Identifier
+			relays = pointer.Relays
+		case "nsec":
+			return nil, fmt.Errorf("%w: refusing to put a private key in a tag", ErrInvalidTag)
+		default:
+			continue
+		}
+
+		// Hints only belong to the tag's main value
+		if i != 1 {
+			continue
+		}
+		if prefix == "naddr" && tag[0] == "e" {
+			tag[0] = "a"
+		}
+		if len(relays) > 0 {
+			tag = setTagField(tag, 2, relays[0])
+		}
+		if author != "" {
+			// NIP-10 puts the author after the marker, NIP-18 right after the relay
+			switch tag[0] {
+			case "e":
+				tag = setTagField(tag, 4, author)
+			case "q":
+				tag = setTagField(tag, 3, author)
+			}
+		}
+	}
+	return tag, nil
+}
+
+// setTagField sets tag[index] to value if it is missing or empty, growing the tag as needed
+func setTagField(tag nostr.Tag, index int, value string) nostr.Tag {
+	for len(tag) <= index {
+		tag = append(tag, "")
+	}
+	if tag[index] == "" {
+		tag[index] = value
+	}
+	return tag
+}
@@ -0,0 +1,124 @@
+package nostr
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+func TestParseTag(t *testing.T) {
+	pubKey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	eventID := "5c83da77af1dec6d7289834998ad7aafbd9e2191396d75ec3cc27f5a77226f36"
+	address := "30023:" + pubKey + ":my-article"
+
+	npub, _ := nip19.EncodePublicKey(pubKey)
+	note, _ := nip19.EncodeNote(eventID)
+	nprofile, _ := nip19.EncodeProfile(pubKey, []string{"wss://profile.example"})
+	nevent, _ := nip19.EncodeEvent(eventID, []string{"wss://event.example"}, pubKey)
+	naddr, _ := nip19.EncodeEntity(pubKey, 30023, "my-article", []string{"wss://addr.example"})
+	nsec, _ := nip19.EncodePrivateKey(nostr.GeneratePrivateKey())
+
+	tests := []struct {
+		name string
+		spec string
+		want nostr.Tag
+	}{
+		{"hashtag", "t,nostr", nostr.Tag{"t", "nostr"}},
+		{"trims the name and values", " t , nostr ", nostr.Tag{"t", "nostr"}},
+		{"name only", "-", nostr.Tag{"-"}},
+		{"JSON array", `["r","https://example.com","read"]`, nostr.Tag{"r", "https://example.com", "read"}},
+		{"hex pubkey", "p," + pubKey, nostr.Tag{"p", pubKey}},
+		{"npub", "p," + npub, nostr.Tag{"p", pubKey}},
+		{"nostr: prefix", "p,nostr:" + npub, nostr.Tag{"p", pubKey}},
+		{"nprofile fills the relay", "p," + nprofile, nostr.Tag{"p", pubKey, "wss://profile.example"}},
+		{"explicit relay wins over the hint", "p," + nprofile + ",wss://mine.example", nostr.Tag{"p", pubKey, "wss://mine.example"}},
+		{"note", "e," + note, nostr.Tag{"e", eventID}},
+		{"nevent fills relay and author", "e," + nevent, nostr.Tag{"e", eventID, "wss://event.example", "", pubKey}},
+		{"nevent keeps the marker", "e," + nevent + ",,reply", nostr.Tag{"e", eventID, "wss://event.example", "reply", pubKey}},
+		{"q from nevent puts the author after the relay", "q," + nevent, nostr.Tag{"q", eventID, "wss://event.example", pubKey}},
+		{"q with an address", "q," + address, nostr.Tag{"q", address}},
+		{"naddr", "a," + naddr, nostr.Tag{"a", address, "wss://addr.example"}},
+		{"e with an naddr becomes a", "e," + naddr, nostr.Tag{"a", address, "wss://addr.example"}},
+		{"entities outside the main value are decoded without hints", "zap," + npub + "," + nprofile, nostr.Tag{"zap", pubKey, pubKey}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTag(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseTag(%q): %v", tt.spec, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ParseTag(%q) = %q, want %q", tt.spec, got, tt.want)
+			}
+		})
+	}
+
+	invalid := []struct {
+		name string
+		spec string
+	}{
+		{"empty", ""},
+		{"empty name", ",nostr"},
+		{"name with spaces", "my tag,value"},
+		{"empty value", "t,"},
+		{"blank value", "t, "},
+		{"bad JSON", `["t",`},
+		{"e without an event ID", "e,nope"},
+		{"p without a key", "p,nope"},
+		{"a without an address", "a," + eventID},
+		{"nsec", "p," + nsec},
+		{"nsec outside the main value", "t,nostr," + nsec},
+	}
+
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if tag, err := ParseTag(tt.spec); !errors.Is(err, ErrInvalidTag) {
+				t.Errorf("ParseTag(%q) = %q, %v, want ErrInvalidTag", tt.spec, tag, err)
+			}
+		})
+	}
+}
+
+func TestParseTags(t *testing.T) {
+	pubKey, _ := nostr.GetPublicKey(nostr.GeneratePrivateKey())
+	npub, _ := nip19.EncodePublicKey(pubKey)
+
+	tests := []struct {
+		name string
+		spec string
+		want nostr.Tags
+	}{
+		{"empty", "", nostr.Tags{}},
+		{"key:value pairs", "t:nostr,t:golang", nostr.Tags{{"t", "nostr"}, {"t", "golang"}}},
+		{"values keep their colons", "r:https://example.com", nostr.Tags{{"r", "https://example.com"}}},
+		{"entities are decoded", "p:" + npub, nostr.Tags{{"p", pubKey}}},
+		{"JSON", `[["t","nostr"],["p","` + npub + `"]]`, nostr.Tags{{"t", "nostr"}, {"p", pubKey}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTags(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseTags(%q): %v", tt.spec, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseTags(%q) = %q, want %q", tt.spec, got, tt.want)
+			}
+			for i := range got {
+				if !slices.Equal(got[i], tt.want[i]) {
+					t.Errorf("ParseTags(%q)[%d] = %q, want %q", tt.spec, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+
+	for _, spec := range []string{"t", "t:", ":nostr", `[["t"`, "p:nope"} {
+		if _, err := ParseTags(spec); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("ParseTags(%q) error = %v, want ErrInvalidTag", spec, err)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package nostr
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrUnsupportedReplyKind = errors.New("NIP-10 replies can only be made to kind 1 notes")
+)
+
+// ThreadNode is an event in a reply tree. Event is nil for a root that couldn't be fetched.
+type ThreadNode struct {
+	ID      string
+	Event   *nostr.Event
+	Replies []*ThreadNode
+}
+
+// threadRefs returns the root and direct parent IDs an event replies to, per NIP-10.
+// Marked e tags are preferred; unmarked ones use the deprecated positional scheme (first is root, last is parent).
+func threadRefs(ev *nostr.Event) (root, parent string) {
+	unmarked := []string{}
+	for _, tag := range ev.Tags {
+		if len(tag) < 2 || tag[0] != "e" {
+			continue
+		}
+		if len(tag) >= 4 {
+			switch tag[3] {
+			case "root":
+				root = tag[1]
+				continue
+			case "reply":
+				parent = tag[1]
+				continue
+			case "mention":
+				continue
+			}
+		}
+		unmarked = append(unmarked, tag[1])
+	}
+
+	if root == "" && parent == "" && len(unmarked) > 0 {
+		root, parent = unmarked[0], unmarked[len(unmarked)-1]
+	}
+
+	// A reply to the root itself only carries the root marker
+	if parent == "" {
+		parent = root
+	}
+	if root == "" {
+		root = parent
+	}
+	return root, parent
+}
+
+// Reply publishes a kind 1 reply to a note referenced by hex ID, note1 or nevent1.
+// It adds NIP-10 root/reply markers with relay hints and notifies everyone in the parent's p tags.
+func (c *Client) Reply(ctx context.Context, parentRef, content string) (*PublishReport, error) {
+	parent, err := c.FetchEvent(ctx, parentRef)
+	if err != nil {
+		return nil, err
+	}
+	if parent.Kind != nostr.KindTextNote {
+		return nil, ErrUnsupportedReplyKind
+	}
+	relayHint := c.eventRelayHint(parentRef)
+
+	// Create the event
+	ev := nostr.Event{
+		PubKey:    c.pubKey,
+		CreatedAt: nostr.Now(),
+		Kind:      nostr.KindTextNote,
+		Tags:      nostr.Tags{},
+		Content:   content,
+	}
+
+	// Point at the thread root, and at the parent if it isn't the root
+	root, _ := threadRefs(parent)
+	if root == "" {
+		ev.Tags = append(ev.Tags, nostr.Tag{"e", parent.ID, relayHint, "root", parent.PubKey})
+	} else {
+		rootTag := nostr.Tag{"e", root, "", "root"}
+		if existing := parent.Tags.FindWithValue("e", root); len(existing) >= 3 {
+			// Reuse the parent's relay hint and author for the root
+			rootTag[2] = existing[2]
+			if len(existing) >= 5 {
+				rootTag = append(rootTag, existing[4])
+			}
+		}
+		ev.Tags = append(ev.Tags, rootTag, nostr.Tag{"e", parent.ID, relayHint, "reply", parent.PubKey})
+	}
+
+	// Notify the parent's author and everyone it notified
+	mentioned := []string{parent.PubKey}
+	for _, tag := range parent.Tags {
+		if len(tag) >= 2 && tag[0] == "p" && nostr.IsValidPublicKey(tag[1]) {
+			mentioned = append(mentioned, tag[1])
+		}
+	}
+	for _, pubKey := range uniqueStrings(mentioned) {
+		if pubKey != c.pubKey {
+			ev.Tags = append(ev.Tags, nostr.Tag{"p", pubKey})
+		}
+	}
+
+	// Sign the event
+	err = c.signer.SignEvent(ctx, &ev)
+	if err != nil {
+		return nil, err
+	}
+
+	// Publish the event to our relays and the inboxes of everyone notified
+	return c.publishRouted(ctx, &ev)
+}
+
+// FetchThread fetches the whole reply tree containing a referenced note and returns its root
+func (c *Client) FetchThread(ctx context.Context, ref string) (*ThreadNode, error) {
+	ev, err := c.FetchEvent(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	rootID, _ := threadRefs(ev)
+	if rootID == "" {
+		rootID = ev.ID
+	}
+
+	// Fetch the root and every note that references it
+	events, err := c.query(ctx, nostr.Filter{IDs: []string{rootID}}, c.Relays())
+	if err != nil {
+		return nil, err
+	}
+	replies, err := c.query(ctx, nostr.Filter{
+		Kinds: []int{nostr.KindTextNote},
+		Tags:  nostr.TagMap{"e": []string{rootID}},
+	}, c.Relays())
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, replies...)
+	events = append(events, ev)
+
+	nodes := map[string]*ThreadNode{rootID: {ID: rootID}}
+	for _, event := range events {
+		// Drop anything a relay forged or mangled, as FetchEvent does
+		if !validEvent(event) {
+			continue
+		}
+		if node, ok := nodes[event.ID]; ok {
+			node.Event = event
+			continue
+		}
+		nodes[event.ID] = &ThreadNode{ID: event.ID, Event: event}
+	}
+
+	// Attach every note to its parent; notes whose parent is missing hang off the root
+	for id, node := range nodes {
+		if id == rootID || node.Event == nil {
+			continue
+		}
+		_, parentID := threadRefs(node.Event)
+		parent, ok := nodes[parentID]
+		if !ok || parentID == id {
+			parent = nodes[rootID]
+		}
+		parent.Replies = append(parent.Replies, node)
+	}
+
+	for _, node := range nodes {
+		sort.Slice(node.Replies, func(i, j int) bool {
+			return node.Replies[i].Event.CreatedAt < node.Replies[j].Event.CreatedAt
+		})
+	}
+
+	return nodes[rootID], nil
+}
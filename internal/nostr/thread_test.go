@@ -0,0 +1,137 @@
+package nostr
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip19"
+)
+
+// reply publishes a reply to ref and returns it as stored by the relay
+func reply(t *testing.T, client *Client, ref, content string) *nostr.Event {
+	t.Helper()
+	report, err := client.Reply(context.Background(), ref, content)
+	if err != nil {
+		t.Fatalf("Reply: %v", err)
+	}
+	return fetchByID(t, client, report.Events[0].EventID)
+}
+
+// tagsNamed returns an event's tags with the given name, in order
+func tagsNamed(event *nostr.Event, name string) []nostr.Tag {
+	tags := []nostr.Tag{}
+	for _, tag := range event.Tags {
+		if tag[0] == name {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func TestReplyToRoot(t *testing.T) {
+	relay := newTestRelay(t)
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+	hint := nostr.NormalizeURL(relay.URL)
+
+	root := postNote(t, alice, "root")
+	event := reply(t, bob, root.ID, "first reply")
+
+	eTags := tagsNamed(event, "e")
+	want := nostr.Tag{"e", root.ID, hint, "root", alice.GetPublicKey()}
+	if len(eTags) != 1 || !slices.Equal(eTags[0], want) {
+		t.Errorf("e tags = %q, want only %q", eTags, want)
+	}
+
+	pTags := tagsNamed(event, "p")
+	if len(pTags) != 1 || !slices.Equal(pTags[0], nostr.Tag{"p", alice.GetPublicKey()}) {
+		t.Errorf("p tags = %q, want the root's author", pTags)
+	}
+}
+
+func TestReplyToReply(t *testing.T) {
+	relay := newTestRelay(t)
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+	carol := newTestClient(t, relay.URL)
+	hint := nostr.NormalizeURL(relay.URL)
+
+	root := postNote(t, alice, "root")
+	first := reply(t, bob, root.ID, "first reply")
+
+	// The nevent's relay hint is used for the parent
+	nevent, _ := nip19.EncodeEvent(first.ID, []string{"wss://hint.example"}, bob.GetPublicKey())
+	event := reply(t, carol, nevent, "second reply")
+
+	eTags := tagsNamed(event, "e")
+	want := []nostr.Tag{
+		{"e", root.ID, hint, "root", alice.GetPublicKey()},
+		{"e", first.ID, "wss://hint.example", "reply", bob.GetPublicKey()},
+	}
+	if !slices.EqualFunc(eTags, want, slices.Equal) {
+		t.Errorf("e tags = %q, want %q", eTags, want)
+	}
+
+	// Both the parent's author and everyone it notified
+	pTags := tagsNamed(event, "p")
+	for _, pubKey := range []string{alice.GetPublicKey(), bob.GetPublicKey()} {
+		if !slices.ContainsFunc(pTags, func(tag nostr.Tag) bool { return tag[1] == pubKey }) {
+			t.Errorf("p tags = %q, missing %s", pTags, pubKey)
+		}
+	}
+	if len(pTags) != 2 {
+		t.Errorf("p tags = %q, want 2", pTags)
+	}
+}
+
+func TestFetchThread(t *testing.T) {
+	relay := newTestRelay(t)
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+	carol := newTestClient(t, relay.URL)
+
+	root := postNote(t, alice, "root")
+	first := reply(t, bob, root.ID, "first reply")
+	second := reply(t, carol, root.ID, "second reply")
+	nested := reply(t, alice, first.ID, "nested reply")
+
+	// A reply whose ID doesn't match its content is left out, even with a valid signature
+	forged := *nested
+	forged.ID = strings.Repeat("ab", 32)
+	relay.AddEvent(&forged)
+
+	// Any note of the thread leads to the whole tree
+	thread, err := carol.FetchThread(context.Background(), nested.ID)
+	if err != nil {
+		t.Fatalf("FetchThread: %v", err)
+	}
+
+	if thread.ID != root.ID || thread.Event == nil {
+		t.Fatalf("thread root = %s, want %s", thread.ID, root.ID)
+	}
+	// Replies posted within the same second may come in either order
+	children := childIDs(thread)
+	if len(children) != 2 || !slices.Contains(children, first.ID) || !slices.Contains(children, second.ID) {
+		t.Fatalf("root replies = %v, want the first and second reply", children)
+	}
+	for _, node := range thread.Replies {
+		want := []string{}
+		if node.ID == first.ID {
+			want = []string{nested.ID}
+		}
+		if got := childIDs(node); !slices.Equal(got, want) {
+			t.Errorf("replies to %s = %v, want %v", node.Event.Content, got, want)
+		}
+	}
+}
+
+func childIDs(node *ThreadNode) []string {
+	ids := make([]string, len(node.Replies))
+	for i, child := range node.Replies {
+		ids[i] = child.ID
+	}
+	return ids
+}
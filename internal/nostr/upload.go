@@ -0,0 +1,253 @@
+package nostr
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+var (
+	ErrUploadFailed        = errors.New("file upload failed")
+	ErrUnknownUploaderType = errors.New("unknown upload server type, expected blossom or nip96")
+)
+
+// FileUploader stores a blob on a media server and returns the URL it can be downloaded from
+type FileUploader interface {
+	Upload(ctx context.Context, data []byte, contentType string) (string, error)
+}
+
+// NewFileUploader returns a Blossom or NIP-96 uploader for a server, authenticated by signer
+func NewFileUploader(kind, server string, signer Signer) (FileUploader, error) {
+	switch kind {
+	case "blossom":
+		return NewBlossomUploader(server, signer), nil
+	case "nip96":
+		return NewNIP96Uploader(server, signer), nil
+	default:
+		return nil, ErrUnknownUploaderType
+	}
+}
+
+// BlossomUploader uploads blobs to a Blossom server (PUT /upload)
+type BlossomUploader struct {
+	Server     string
+	Signer     Signer
+	HTTPClient *http.Client
+}
+
+// NewBlossomUploader creates an uploader for a Blossom server such as https://blossom.example.com
+func NewBlossomUploader(server string, signer Signer) *BlossomUploader {
+	return &BlossomUploader{
+		Server:     strings.TrimSuffix(server, "/"),
+		Signer:     signer,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Upload sends the blob with a kind 24242 authorization event and returns its URL
+func (u *BlossomUploader) Upload(ctx context.Context, data []byte, contentType string) (string, error) {
+	hash := sha256.Sum256(data)
+	hashHex := hex.EncodeToString(hash[:])
+
+	// Authorize the upload of this exact blob for a few minutes
+	auth := &nostr.Event{
+		Kind:      24242,
+		CreatedAt: nostr.Now(),
+		Content:   "Upload " + hashHex,
+		Tags: nostr.Tags{
+			{"t", "upload"},
+			{"x", hashHex},
+			{"expiration", strconv.FormatInt(time.Now().Add(5*time.Minute).Unix(), 10)},
+		},
+	}
+	header, err := authorizationHeader(ctx, u.Signer, auth)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.Server+"/upload", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", header)
+	req.Header.Set("Content-Type", contentType)
+
+	var descriptor struct {
+		URL    string `json:"url"`
+		SHA256 string `json:"sha256"`
+	}
+	if err := doJSON(u.HTTPClient, req, &descriptor); err != nil {
+		return "", err
+	}
+
+	if descriptor.URL == "" {
+		return "", fmt.Errorf("%w: server returned no URL", ErrUploadFailed)
+	}
+	if descriptor.SHA256 != "" && descriptor.SHA256 != hashHex {
+		return "", fmt.Errorf("%w: server stored a different blob", ErrUploadFailed)
+	}
+
+	return descriptor.URL, nil
+}
+
+// NIP96Uploader uploads files to a NIP-96 HTTP file storage server
+type NIP96Uploader struct {
+	Server     string
+	Signer     Signer
+	HTTPClient *http.Client
+}
+
+// NewNIP96Uploader creates an uploader for a NIP-96 server; its API URL is discovered on first upload
+func NewNIP96Uploader(server string, signer Signer) *NIP96Uploader {
+	return &NIP96Uploader{
+		Server:     strings.TrimSuffix(server, "/"),
+		Signer:     signer,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Upload posts the file as multipart form data with a NIP-98 authorization event and returns its URL
+func (u *NIP96Uploader) Upload(ctx context.Context, data []byte, contentType string) (string, error) {
+	apiURL, err := u.apiURL(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// Build the multipart body; encrypted blobs must not be resized or re-encoded
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, err := form.CreateFormFile("file", "blob")
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", err
+	}
+	fields := [][2]string{
+		{"size", strconv.Itoa(len(data))},
+		{"content_type", contentType},
+		{"no_transform", "true"},
+	}
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return "", err
+		}
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(data)
+	auth := &nostr.Event{
+		Kind:      27235,
+		CreatedAt: nostr.Now(),
+		Tags: nostr.Tags{
+			{"u", apiURL},
+			{"method", http.MethodPost},
+			{"payload", hex.EncodeToString(hash[:])},
+		},
+	}
+	header, err := authorizationHeader(ctx, u.Signer, auth)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", header)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	var response struct {
+		Status     string `json:"status"`
+		Message    string `json:"message"`
+		NIP94Event struct {
+			Tags nostr.Tags `json:"tags"`
+		} `json:"nip94_event"`
+	}
+	if err := doJSON(u.HTTPClient, req, &response); err != nil {
+		return "", err
+	}
+
+	if response.Status != "success" {
+		return "", fmt.Errorf("%w: %s", ErrUploadFailed, response.Message)
+	}
+	if tag := response.NIP94Event.Tags.Find("url"); tag != nil {
+		return tag[1], nil
+	}
+
+	return "", fmt.Errorf("%w: server returned no URL", ErrUploadFailed)
+}
+
+// apiURL reads the upload endpoint from the server's /.well-known/nostr/nip96.json
+func (u *NIP96Uploader) apiURL(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.Server+"/.well-known/nostr/nip96.json", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var info struct {
+		APIURL string `json:"api_url"`
+	}
+	if err := doJSON(u.HTTPClient, req, &info); err != nil {
+		return "", err
+	}
+
+	if info.APIURL == "" {
+		return "", fmt.Errorf("%w: server does not advertise an api_url", ErrUploadFailed)
+	}
+	return info.APIURL, nil
+}
+
+// authorizationHeader signs an auth event and encodes it as a "Nostr <base64>" header value
+func authorizationHeader(ctx context.Context, signer Signer, event *nostr.Event) (string, error) {
+	if err := signer.SignEvent(ctx, event); err != nil {
+		return "", err
+	}
+
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+
+	return "Nostr " + base64.StdEncoding.EncodeToString(eventJSON), nil
+}
+
+// doJSON performs a request and decodes a JSON response, turning HTTP errors into ErrUploadFailed
+func doJSON(client *http.Client, req *http.Request, result any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUploadFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		// Blossom servers explain failures in X-Reason
+		reason := resp.Header.Get("X-Reason")
+		if reason == "" {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			reason = strings.TrimSpace(string(body))
+		}
+		return fmt.Errorf("%w: %s returned %d: %s", ErrUploadFailed, req.URL, resp.StatusCode, reason)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,250 @@
+package nostr
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// blobServer is an httptest media server speaking either Blossom or NIP-96.
+// It checks the authorization event of every upload and serves stored blobs by hash.
+type blobServer struct {
+	*httptest.Server
+	t *testing.T
+
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func newBlobServer(t *testing.T, kind string) *blobServer {
+	t.Helper()
+	s := &blobServer{t: t, blobs: make(map[string][]byte)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{hash}", s.serveBlob)
+	switch kind {
+	case "blossom":
+		mux.HandleFunc("PUT /upload", s.blossomUpload)
+	case "nip96":
+		mux.HandleFunc("GET /.well-known/nostr/nip96.json", func(w http.ResponseWriter, r *http.Request) {
+			json.NewEncoder(w).Encode(map[string]string{"api_url": s.URL + "/api/upload"})
+		})
+		mux.HandleFunc("POST /api/upload", s.nip96Upload)
+	}
+
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// authEvent decodes and verifies a "Nostr <base64>" authorization header
+func (s *blobServer) authEvent(r *http.Request, kind int) *nostr.Event {
+	encoded, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Nostr ")
+	if !ok {
+		return nil
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil
+	}
+	event := &nostr.Event{}
+	if err := json.Unmarshal(data, event); err != nil || event.Kind != kind {
+		return nil
+	}
+	if ok, _ := event.CheckSignature(); !ok {
+		return nil
+	}
+	return event
+}
+
+// store saves a blob and returns its hash
+func (s *blobServer) store(data []byte) string {
+	hash := sha256.Sum256(data)
+	hashHex := hex.EncodeToString(hash[:])
+
+	s.mu.Lock()
+	s.blobs[hashHex] = data
+	s.mu.Unlock()
+
+	return hashHex
+}
+
+func (s *blobServer) serveBlob(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data, ok := s.blobs[r.PathValue("hash")]
+	s.mu.Unlock()
+
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(data)
+}
+
+func (s *blobServer) blossomUpload(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	hash := sha256.Sum256(data)
+
+	auth := s.authEvent(r, 24242)
+	if auth == nil || auth.Tags.FindWithValue("x", hex.EncodeToString(hash[:])) == nil {
+		w.Header().Set("X-Reason", "bad authorization")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	hashHex := s.store(data)
+	json.NewEncoder(w).Encode(map[string]string{"url": s.URL + "/" + hashHex, "sha256": hashHex})
+}
+
+func (s *blobServer) nip96Upload(w http.ResponseWriter, r *http.Request) {
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, _ := io.ReadAll(file)
+	hash := sha256.Sum256(data)
+
+	auth := s.authEvent(r, 27235)
+	if auth == nil || auth.Tags.FindWithValue("payload", hex.EncodeToString(hash[:])) == nil {
+		http.Error(w, "bad authorization", http.StatusUnauthorized)
+		return
+	}
+	if r.FormValue("no_transform") != "true" {
+		s.t.Error("NIP-96 upload did not ask the server to keep the blob as is")
+	}
+
+	hashHex := s.store(data)
+	json.NewEncoder(w).Encode(map[string]any{
+		"status":      "success",
+		"nip94_event": map[string]any{"tags": nostr.Tags{{"url", s.URL + "/" + hashHex}, {"x", hashHex}}},
+	})
+}
+
+func TestFileUploadRoundTrip(t *testing.T) {
+	for _, kind := range []string{"blossom", "nip96"} {
+		t.Run(kind, func(t *testing.T) {
+			server := newBlobServer(t, kind)
+			signer, _ := NewKeySigner(nostr.GeneratePrivateKey())
+			uploader, err := NewFileUploader(kind, server.URL, signer)
+			if err != nil {
+				t.Fatalf("NewFileUploader: %v", err)
+			}
+
+			plaintext := []byte("a file only the recipient can read")
+			ciphertext, file, err := encryptFile(plaintext)
+			if err != nil {
+				t.Fatalf("encryptFile: %v", err)
+			}
+
+			file.URL, err = uploader.Upload(context.Background(), ciphertext, "application/octet-stream")
+			if err != nil {
+				t.Fatalf("Upload: %v", err)
+			}
+
+			downloaded, err := file.Download(context.Background())
+			if err != nil {
+				t.Fatalf("Download: %v", err)
+			}
+			if !bytes.Equal(downloaded, plaintext) {
+				t.Errorf("downloaded %q, want %q", downloaded, plaintext)
+			}
+		})
+	}
+}
+
+func TestBlossomUploadRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Reason", "quota exceeded")
+		w.WriteHeader(http.StatusPaymentRequired)
+	}))
+	defer server.Close()
+
+	signer, _ := NewKeySigner(nostr.GeneratePrivateKey())
+	_, err := NewBlossomUploader(server.URL, signer).Upload(context.Background(), []byte("blob"), "text/plain")
+	if !errors.Is(err, ErrUploadFailed) || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("Upload error = %v, want ErrUploadFailed with the server's reason", err)
+	}
+}
+
+func TestFileDownloadChecksSize(t *testing.T) {
+	server := newBlobServer(t, "blossom")
+
+	_, file, err := encryptFile([]byte("declared size"))
+	if err != nil {
+		t.Fatalf("encryptFile: %v", err)
+	}
+
+	// The server holds more bytes than the message declared
+	oversized := bytes.Repeat([]byte{0}, int(file.Size)+10)
+	file.URL = server.URL + "/" + server.store(oversized)
+	if _, err := file.Download(context.Background()); !errors.Is(err, ErrFileSizeMismatch) {
+		t.Errorf("oversized download error = %v, want ErrFileSizeMismatch", err)
+	}
+
+	// And fewer
+	file.URL = server.URL + "/" + server.store([]byte("short"))
+	if _, err := file.Download(context.Background()); !errors.Is(err, ErrFileSizeMismatch) {
+		t.Errorf("truncated download error = %v, want ErrFileSizeMismatch", err)
+	}
+
+	// A declared size above the limit is refused before downloading anything
+	file.Size = MaxFileDownloadSize + 1
+	if _, err := file.Download(context.Background()); !errors.Is(err, ErrFileTooLarge) {
+		t.Errorf("download error = %v, want ErrFileTooLarge", err)
+	}
+}
+
+func TestSendNIP17FileRoundTrip(t *testing.T) {
+	relay := newTestRelay(t)
+	server := newBlobServer(t, "blossom")
+	ctx := context.Background()
+
+	alice := newTestClient(t, relay.URL)
+	bob := newTestClient(t, relay.URL)
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("meeting notes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	uploader := NewBlossomUploader(server.URL, alice.Signer())
+	if _, err := alice.SendNIP17File(ctx, []string{bob.GetPublicKeyBech32()}, path, uploader, "", "", "", 0); err != nil {
+		t.Fatalf("SendNIP17File: %v", err)
+	}
+
+	messages, err := bob.FetchNIP17Messages(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("FetchNIP17Messages: %v", err)
+	}
+	if len(messages) != 1 || messages[0].File == nil {
+		t.Fatalf("got %+v, want one file message", messages)
+	}
+
+	file := messages[0].File
+	if file.Type != "text/plain" {
+		t.Errorf("file type = %q, want text/plain", file.Type)
+	}
+	data, err := file.Download(ctx)
+	if err != nil {
+		t.Fatalf("Download: %v", err)
+	}
+	if string(data) != "meeting notes" {
+		t.Errorf("downloaded %q", data)
+	}
+}
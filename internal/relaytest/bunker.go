@@ -0,0 +1,221 @@
+package relaytest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip04"
+	"github.com/nbd-wtf/go-nostr/nip44"
+	"github.com/nbd-wtf/go-nostr/nip46"
+)
+
+// Bunker is a NIP-46 remote signer that answers requests sent to it through a Relay,
+// signing and encrypting with a single secret key
+type Bunker struct {
+	// SecretKey and PublicKey are the key the bunker signs with
+	SecretKey string
+	PublicKey string
+
+	relay *Relay
+	ws    *websocket.Conn
+	wg    sync.WaitGroup
+
+	mu      sync.Mutex
+	methods []string
+	tamper  func(event *nostr.Event)
+}
+
+// NewBunker starts a bunker with a random key listening on relay
+func NewBunker(relay *Relay) (*Bunker, error) {
+	secretKey := nostr.GeneratePrivateKey()
+	publicKey, _ := nostr.GetPublicKey(secretKey)
+
+	ws, _, err := websocket.DefaultDialer.Dial(relay.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req := nostr.ReqEnvelope{SubscriptionID: "bunker", Filters: nostr.Filters{{
+		Kinds: []int{nostr.KindNostrConnect},
+		Tags:  nostr.TagMap{"p": []string{publicKey}},
+	}}}
+	data, _ := req.MarshalJSON()
+	if err := ws.WriteMessage(websocket.TextMessage, data); err != nil {
+		ws.Close()
+		return nil, err
+	}
+
+	b := &Bunker{
+		SecretKey: secretKey,
+		PublicKey: publicKey,
+		relay:     relay,
+		ws:        ws,
+	}
+
+	b.wg.Add(1)
+	go b.serve()
+
+	return b, nil
+}
+
+// URI returns the bunker:// connection string for this bunker
+func (b *Bunker) URI() string {
+	return fmt.Sprintf("bunker://%s?relay=%s", b.PublicKey, b.relay.URL)
+}
+
+// Methods returns the NIP-46 methods the bunker has answered, in order
+func (b *Bunker) Methods() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	methods := make([]string, len(b.methods))
+	copy(methods, b.methods)
+	return methods
+}
+
+// Tamper makes the bunker pass every event it signs to fn before sending it back,
+// to test clients against a misbehaving signer
+func (b *Bunker) Tamper(fn func(event *nostr.Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tamper = fn
+}
+
+// Close stops answering requests. A request being answered is finished before
+// the connection is closed.
+func (b *Bunker) Close() {
+	// An expired read deadline wakes the reader without closing the socket under a response
+	b.ws.SetReadDeadline(time.Now())
+	b.wg.Wait()
+	b.ws.Close()
+}
+
+// serve answers requests one at a time until Close
+func (b *Bunker) serve() {
+	defer b.wg.Done()
+
+	parser := nostr.NewMessageParser()
+	for {
+		_, message, err := b.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		envelope, err := parser.ParseMessage(string(message))
+		if err != nil {
+			continue
+		}
+		if env, ok := envelope.(*nostr.EventEnvelope); ok {
+			b.handle(&env.Event)
+		}
+	}
+}
+
+func (b *Bunker) handle(event *nostr.Event) {
+	conversationKey, err := nip44.GenerateConversationKey(event.PubKey, b.SecretKey)
+	if err != nil {
+		return
+	}
+	plaintext, err := nip44.Decrypt(event.Content, conversationKey)
+	if err != nil {
+		return
+	}
+
+	var req nip46.Request
+	if err := json.Unmarshal([]byte(plaintext), &req); err != nil {
+		return
+	}
+
+	response := nip46.Response{ID: req.ID}
+	response.Result, err = b.answer(req)
+	if err != nil {
+		response.Error = err.Error()
+	}
+
+	b.mu.Lock()
+	b.methods = append(b.methods, req.Method)
+	b.mu.Unlock()
+
+	data, _ := json.Marshal(response)
+	content, err := nip44.Encrypt(string(data), conversationKey)
+	if err != nil {
+		return
+	}
+
+	reply := nostr.Event{
+		Kind:      nostr.KindNostrConnect,
+		CreatedAt: nostr.Now(),
+		Tags:      nostr.Tags{{"p", event.PubKey}},
+		Content:   content,
+	}
+	if err := reply.Sign(b.SecretKey); err != nil {
+		return
+	}
+
+	env := nostr.EventEnvelope{Event: reply}
+	data, _ = env.MarshalJSON()
+	b.ws.WriteMessage(websocket.TextMessage, data)
+}
+
+// answer runs a single request and returns its result
+func (b *Bunker) answer(req nip46.Request) (string, error) {
+	need := map[string]int{"sign_event": 1, "nip04_encrypt": 2, "nip04_decrypt": 2, "nip44_encrypt": 2, "nip44_decrypt": 2}
+	if len(req.Params) < need[req.Method] {
+		return "", errors.New("missing parameters")
+	}
+
+	switch req.Method {
+	case "connect":
+		return "ack", nil
+
+	case "get_public_key":
+		return b.PublicKey, nil
+
+	case "sign_event":
+		var event nostr.Event
+		if err := json.Unmarshal([]byte(req.Params[0]), &event); err != nil {
+			return "", err
+		}
+		if err := event.Sign(b.SecretKey); err != nil {
+			return "", err
+		}
+
+		b.mu.Lock()
+		tamper := b.tamper
+		b.mu.Unlock()
+		if tamper != nil {
+			tamper(&event)
+		}
+
+		data, err := json.Marshal(event)
+		return string(data), err
+
+	case "nip04_encrypt", "nip04_decrypt":
+		sharedSecret, err := nip04.ComputeSharedSecret(req.Params[0], b.SecretKey)
+		if err != nil {
+			return "", err
+		}
+		if req.Method == "nip04_encrypt" {
+			return nip04.Encrypt(req.Params[1], sharedSecret)
+		}
+		return nip04.Decrypt(req.Params[1], sharedSecret)
+
+	case "nip44_encrypt", "nip44_decrypt":
+		conversationKey, err := nip44.GenerateConversationKey(req.Params[0], b.SecretKey)
+		if err != nil {
+			return "", err
+		}
+		if req.Method == "nip44_encrypt" {
+			return nip44.Encrypt(req.Params[1], conversationKey)
+		}
+		return nip44.Decrypt(req.Params[1], conversationKey)
+
+	default:
+		return "", fmt.Errorf("unsupported method %s", req.Method)
+	}
+}
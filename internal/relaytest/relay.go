@@ -0,0 +1,332 @@
+// Package relaytest provides an in-process NIP-01 relay for offline integration tests.
+package relaytest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/nbd-wtf/go-nostr"
+	"github.com/nbd-wtf/go-nostr/nip11"
+)
+
+// Relay is a minimal in-memory NIP-01 relay served from an httptest.Server.
+// It supports EVENT, REQ, CLOSE, EOSE and OK, replaceable and addressable events,
+// and live delivery of new events to open subscriptions.
+type Relay struct {
+	// URL is the ws:// address clients should connect to
+	URL string
+
+	// Reject, if set, is consulted for every incoming event. A non-empty return value
+	// rejects the event with that message as the OK reason.
+	Reject func(event *nostr.Event) string
+
+	// Info is served as the NIP-11 relay information document; tests may change it before connecting
+	Info nip11.RelayInformationDocument
+
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	mu          sync.Mutex
+	events      []*nostr.Event
+	conns       map[*conn]bool
+	infoFetches int
+}
+
+// conn is one client websocket with its open subscriptions
+type conn struct {
+	ws      *websocket.Conn
+	writeMu sync.Mutex
+
+	mu   sync.Mutex
+	subs map[string]nostr.Filters
+}
+
+// NewRelay starts a relay listening on a random local port
+func NewRelay() *Relay {
+	r := &Relay{
+		Info: nip11.RelayInformationDocument{
+			Name:          "relaytest",
+			SupportedNIPs: []any{1, 11},
+		},
+		conns: make(map[*conn]bool),
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+	}
+
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	r.URL = "ws" + strings.TrimPrefix(r.server.URL, "http")
+
+	return r
+}
+
+// Close disconnects every client and stops the server
+func (r *Relay) Close() {
+	r.mu.Lock()
+	for c := range r.conns {
+		c.ws.Close()
+	}
+	r.mu.Unlock()
+
+	r.server.Close()
+}
+
+// DropConnections closes every client websocket without stopping the server,
+// simulating a relay restart
+func (r *Relay) DropConnections() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for c := range r.conns {
+		c.ws.Close()
+	}
+}
+
+// Events returns a snapshot of every stored event, oldest first
+func (r *Relay) Events() []*nostr.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]*nostr.Event, len(r.events))
+	copy(events, r.events)
+	return events
+}
+
+// Query returns stored events matching a filter, newest first, honoring its limit
+func (r *Relay) Query(filter nostr.Filter) []*nostr.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.queryLocked(filter)
+}
+
+// InfoFetches returns how many times the NIP-11 document was requested
+func (r *Relay) InfoFetches() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.infoFetches
+}
+
+// AddEvent stores an event directly, bypassing validation, and delivers it to subscribers
+func (r *Relay) AddEvent(event *nostr.Event) {
+	r.store(event)
+}
+
+func (r *Relay) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Header.Get("Accept") == "application/nostr+json" {
+		r.mu.Lock()
+		r.infoFetches++
+		r.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/nostr+json")
+		json.NewEncoder(w).Encode(r.Info)
+		return
+	}
+
+	ws, err := r.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		return
+	}
+
+	c := &conn{ws: ws, subs: make(map[string]nostr.Filters)}
+
+	r.mu.Lock()
+	r.conns[c] = true
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		delete(r.conns, c)
+		r.mu.Unlock()
+		ws.Close()
+	}()
+
+	parser := nostr.NewMessageParser()
+	for {
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		envelope, err := parser.ParseMessage(string(message))
+		if err != nil || envelope == nil {
+			notice := nostr.NoticeEnvelope("error: could not parse message")
+			c.send(&notice)
+			continue
+		}
+
+		switch env := envelope.(type) {
+		case *nostr.EventEnvelope:
+			r.handleEvent(c, &env.Event)
+
+		case *nostr.ReqEnvelope:
+			r.handleReq(c, env)
+
+		case *nostr.CloseEnvelope:
+			c.mu.Lock()
+			delete(c.subs, string(*env))
+			c.mu.Unlock()
+		}
+	}
+}
+
+func (r *Relay) handleEvent(c *conn, event *nostr.Event) {
+	if !event.CheckID() {
+		c.send(&nostr.OKEnvelope{EventID: event.ID, OK: false, Reason: "invalid: event id does not match"})
+		return
+	}
+	if ok, _ := event.CheckSignature(); !ok {
+		c.send(&nostr.OKEnvelope{EventID: event.ID, OK: false, Reason: "invalid: signature verification failed"})
+		return
+	}
+	if r.Reject != nil {
+		if reason := r.Reject(event); reason != "" {
+			c.send(&nostr.OKEnvelope{EventID: event.ID, OK: false, Reason: reason})
+			return
+		}
+	}
+
+	if r.has(event.ID) {
+		c.send(&nostr.OKEnvelope{EventID: event.ID, OK: true, Reason: "duplicate: already have this event"})
+		return
+	}
+
+	r.store(event)
+	c.send(&nostr.OKEnvelope{EventID: event.ID, OK: true})
+}
+
+func (r *Relay) handleReq(c *conn, env *nostr.ReqEnvelope) {
+	c.mu.Lock()
+	c.subs[env.SubscriptionID] = env.Filters
+	c.mu.Unlock()
+
+	// Collect stored events for every filter under the lock, then send them and EOSE without it
+	sent := make(map[string]bool)
+	matching := []*nostr.Event{}
+	r.mu.Lock()
+	for _, filter := range env.Filters {
+		for _, event := range r.queryLocked(filter) {
+			if sent[event.ID] {
+				continue
+			}
+			sent[event.ID] = true
+			matching = append(matching, event)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, event := range matching {
+		c.send(&nostr.EventEnvelope{SubscriptionID: &env.SubscriptionID, Event: *event})
+	}
+
+	eose := nostr.EOSEEnvelope(env.SubscriptionID)
+	c.send(&eose)
+}
+
+// store saves an event, replacing older versions of replaceable and addressable events,
+// and broadcasts it to every matching live subscription
+func (r *Relay) store(event *nostr.Event) {
+	r.mu.Lock()
+
+	if nostr.IsReplaceableKind(event.Kind) || nostr.IsAddressableKind(event.Kind) {
+		kept := r.events[:0]
+		for _, existing := range r.events {
+			if sameAddress(existing, event) {
+				if existing.CreatedAt > event.CreatedAt {
+					// We already have a newer version, ignore this one
+					r.mu.Unlock()
+					return
+				}
+				continue
+			}
+			kept = append(kept, existing)
+		}
+		r.events = kept
+	}
+
+	r.events = append(r.events, event)
+
+	conns := make([]*conn, 0, len(r.conns))
+	for c := range r.conns {
+		conns = append(conns, c)
+	}
+	r.mu.Unlock()
+
+	for _, c := range conns {
+		c.mu.Lock()
+		matching := []string{}
+		for id, filters := range c.subs {
+			if filters.Match(event) {
+				matching = append(matching, id)
+			}
+		}
+		c.mu.Unlock()
+
+		for _, id := range matching {
+			c.send(&nostr.EventEnvelope{SubscriptionID: &id, Event: *event})
+		}
+	}
+}
+
+func (r *Relay) has(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range r.events {
+		if event.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// queryLocked must be called with r.mu held
+func (r *Relay) queryLocked(filter nostr.Filter) []*nostr.Event {
+	if filter.LimitZero {
+		return nil
+	}
+
+	results := []*nostr.Event{}
+	for _, event := range r.events {
+		if filter.Matches(event) {
+			results = append(results, event)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].CreatedAt > results[j].CreatedAt
+	})
+
+	if filter.Limit > 0 && len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+
+	return results
+}
+
+// sameAddress reports whether two events replace each other (same kind, author and d tag)
+func sameAddress(a, b *nostr.Event) bool {
+	if a.Kind != b.Kind || a.PubKey != b.PubKey {
+		return false
+	}
+	if nostr.IsAddressableKind(a.Kind) {
+		return a.Tags.GetD() == b.Tags.GetD()
+	}
+	return true
+}
+
+func (c *conn) send(envelope nostr.Envelope) {
+	data, err := envelope.MarshalJSON()
+	if err != nil {
+		return
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.ws.WriteMessage(websocket.TextMessage, data)
+}
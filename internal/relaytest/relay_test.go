@@ -0,0 +1,217 @@
+package relaytest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/nbd-wtf/go-nostr"
+)
+
+// client is a raw websocket connection speaking NIP-01 to the test relay
+type client struct {
+	t      *testing.T
+	ws     *websocket.Conn
+	parser nostr.MessageParser
+}
+
+func dial(t *testing.T, relay *Relay) *client {
+	t.Helper()
+	ws, _, err := websocket.DefaultDialer.Dial(relay.URL, nil)
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	return &client{t: t, ws: ws, parser: nostr.NewMessageParser()}
+}
+
+func (c *client) write(message ...any) {
+	c.t.Helper()
+	data, _ := json.Marshal(message)
+	if err := c.ws.WriteMessage(websocket.TextMessage, data); err != nil {
+		c.t.Fatalf("WriteMessage: %v", err)
+	}
+}
+
+func (c *client) read() nostr.Envelope {
+	c.t.Helper()
+	c.ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := c.ws.ReadMessage()
+	if err != nil {
+		c.t.Fatalf("ReadMessage: %v", err)
+	}
+	envelope, err := c.parser.ParseMessage(string(data))
+	if err != nil {
+		c.t.Fatalf("ParseMessage %s: %v", data, err)
+	}
+	return envelope
+}
+
+func (c *client) readOK() *nostr.OKEnvelope {
+	c.t.Helper()
+	ok, isOK := c.read().(*nostr.OKEnvelope)
+	if !isOK {
+		c.t.Fatal("expected OK")
+	}
+	return ok
+}
+
+// readUntilEOSE returns the events sent for a subscription before its EOSE
+func (c *client) readUntilEOSE(subID string) []nostr.Event {
+	c.t.Helper()
+	events := []nostr.Event{}
+	for {
+		switch env := c.read().(type) {
+		case *nostr.EventEnvelope:
+			if *env.SubscriptionID != subID {
+				c.t.Fatalf("event for subscription %s, want %s", *env.SubscriptionID, subID)
+			}
+			events = append(events, env.Event)
+		case *nostr.EOSEEnvelope:
+			if string(*env) != subID {
+				c.t.Fatalf("EOSE for %s, want %s", string(*env), subID)
+			}
+			return events
+		default:
+			c.t.Fatalf("unexpected %T", env)
+		}
+	}
+}
+
+func signed(t *testing.T, sk string, kind int, createdAt nostr.Timestamp, tags nostr.Tags, content string) nostr.Event {
+	t.Helper()
+	ev := nostr.Event{Kind: kind, CreatedAt: createdAt, Tags: tags, Content: content}
+	if err := ev.Sign(sk); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return ev
+}
+
+func TestEventIsAcknowledged(t *testing.T) {
+	relay := NewRelay()
+	defer relay.Close()
+	c := dial(t, relay)
+	sk := nostr.GeneratePrivateKey()
+
+	ev := signed(t, sk, 1, nostr.Now(), nil, "hello")
+	c.write("EVENT", ev)
+	if ok := c.readOK(); !ok.OK || ok.EventID != ev.ID {
+		t.Fatalf("OK = %+v, want accepted", ok)
+	}
+
+	c.write("EVENT", ev)
+	if ok := c.readOK(); !ok.OK || ok.Reason == "" {
+		t.Errorf("duplicate OK = %+v, want accepted with a duplicate reason", ok)
+	}
+
+	forged := ev
+	forged.Content = "changed"
+	c.write("EVENT", forged)
+	if ok := c.readOK(); ok.OK {
+		t.Error("accepted an event whose ID doesn't match")
+	}
+
+	relay.Reject = func(*nostr.Event) string { return "blocked: test" }
+	c.write("EVENT", signed(t, sk, 1, nostr.Now(), nil, "rejected"))
+	if ok := c.readOK(); ok.OK || ok.Reason != "blocked: test" {
+		t.Errorf("OK = %+v, want rejected by Reject", ok)
+	}
+
+	if n := len(relay.Events()); n != 1 {
+		t.Errorf("stored %d events, want 1", n)
+	}
+}
+
+func TestReqSendsStoredEventsThenEOSE(t *testing.T) {
+	relay := NewRelay()
+	defer relay.Close()
+	c := dial(t, relay)
+	sk := nostr.GeneratePrivateKey()
+
+	now := nostr.Now()
+	for i := 0; i < 3; i++ {
+		ev := signed(t, sk, 1, now+nostr.Timestamp(i), nil, "note")
+		relay.AddEvent(&ev)
+	}
+	relay.AddEvent(&nostr.Event{Kind: 7, CreatedAt: now})
+
+	c.write("REQ", "sub", nostr.Filter{Kinds: []int{1}, Limit: 2})
+	events := c.readUntilEOSE("sub")
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want the limit of 2", len(events))
+	}
+	if events[0].CreatedAt < events[1].CreatedAt {
+		t.Error("events not sent newest first")
+	}
+}
+
+func TestLiveEventsStopAfterClose(t *testing.T) {
+	relay := NewRelay()
+	defer relay.Close()
+	subscriber, publisher := dial(t, relay), dial(t, relay)
+	sk := nostr.GeneratePrivateKey()
+
+	subscriber.write("REQ", "live", nostr.Filter{Kinds: []int{1}})
+	if events := subscriber.readUntilEOSE("live"); len(events) != 0 {
+		t.Fatalf("got %d stored events from an empty relay", len(events))
+	}
+
+	ev := signed(t, sk, 1, nostr.Now(), nil, "live")
+	publisher.write("EVENT", ev)
+	publisher.readOK()
+	env, ok := subscriber.read().(*nostr.EventEnvelope)
+	if !ok || env.Event.ID != ev.ID {
+		t.Fatal("subscriber didn't receive the live event")
+	}
+
+	// After CLOSE, a new event must not be delivered. The subscriber's own EVENT round trip
+	// proves the relay has handled its CLOSE before the event is stored.
+	subscriber.write("CLOSE", "live")
+	subscriber.write("EVENT", signed(t, sk, 1, nostr.Now(), nil, "after close"))
+	if _, isOK := subscriber.read().(*nostr.OKEnvelope); !isOK {
+		t.Fatal("received an event on a closed subscription")
+	}
+}
+
+func TestReplaceableEventsKeepNewest(t *testing.T) {
+	relay := NewRelay()
+	defer relay.Close()
+	c := dial(t, relay)
+	sk := nostr.GeneratePrivateKey()
+	now := nostr.Now()
+
+	older := signed(t, sk, 0, now-10, nil, `{"name":"old"}`)
+	newer := signed(t, sk, 0, now, nil, `{"name":"new"}`)
+	for _, ev := range []nostr.Event{older, newer, older} {
+		c.write("EVENT", ev)
+		c.readOK()
+	}
+
+	c.write("REQ", "meta", nostr.Filter{Kinds: []int{0}})
+	events := c.readUntilEOSE("meta")
+	if len(events) != 1 || events[0].ID != newer.ID {
+		t.Fatalf("got %d metadata events, want only the newest", len(events))
+	}
+
+	// Addressable events are replaced per d tag
+	for _, ev := range []nostr.Event{
+		signed(t, sk, 30023, now-1, nostr.Tags{{"d", "a"}}, "a v1"),
+		signed(t, sk, 30023, now, nostr.Tags{{"d", "a"}}, "a v2"),
+		signed(t, sk, 30023, now, nostr.Tags{{"d", "b"}}, "b v1"),
+	} {
+		c.write("EVENT", ev)
+		c.readOK()
+	}
+
+	c.write("REQ", "articles", nostr.Filter{Kinds: []int{30023}})
+	events = c.readUntilEOSE("articles")
+	if len(events) != 2 {
+		t.Fatalf("got %d articles, want one per d tag", len(events))
+	}
+	for _, ev := range events {
+		if ev.Content == "a v1" {
+			t.Error("replaced article version still served")
+		}
+	}
+}